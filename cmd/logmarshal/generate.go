@@ -0,0 +1,555 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxResolveDepth Nesting level of the named types & the embedded structs resolved
+// before giving up, to survive cycles
+const maxResolveDepth = 32
+
+// basicMethods Suffix of the ObjectEncoder Add & ArrayEncoder Append methods by basic type
+var basicMethods = map[string]string{
+	"string":     "String",
+	"bool":       "Bool",
+	"int":        "Int",
+	"int8":       "Int8",
+	"int16":      "Int16",
+	"int32":      "Int32",
+	"rune":       "Int32",
+	"int64":      "Int64",
+	"uint":       "Uint",
+	"uint8":      "Uint8",
+	"byte":       "Uint8",
+	"uint16":     "Uint16",
+	"uint32":     "Uint32",
+	"uint64":     "Uint64",
+	"uintptr":    "Uintptr",
+	"float32":    "Float32",
+	"float64":    "Float64",
+	"complex64":  "Complex64",
+	"complex128": "Complex128",
+}
+
+type valueKind int
+
+const (
+	// kindTagged Encoded with logger.AddTagged at runtime
+	kindTagged valueKind = iota
+	kindBasic
+	kindBytes
+	kindTime
+	kindDuration
+	kindError
+	// kindObject Value with a MarshalLogObject method
+	kindObject
+	// kindArray Value with a MarshalLogArray method
+	kindArray
+	kindPointer
+	kindSlice
+)
+
+// valueType Resolved type of a field or an element
+type valueType struct {
+	kind valueKind
+	// basic Basic type of a kindBasic, converted to it if named
+	basic string
+	named bool
+	// nilable Whether the value can be nil, written as null then
+	nilable bool
+	// empty Check of omitempty, %s being the value, empty if it's checked at runtime
+	empty string
+	elem  *valueType
+}
+
+// generator Writes the methods of the requested types
+type generator struct {
+	pkg       *pkgInfo
+	requested map[string]bool
+	buf       bytes.Buffer
+	// usesLogger Whether the code refers to the logger package
+	usesLogger bool
+	// vars Counter naming the variables of the nested closures & loops
+	vars int
+}
+
+// generate Get the formatted source of the methods of the types
+func generate(pkg *pkgInfo, types []string) ([]byte, error) {
+	g := &generator{pkg: pkg, requested: make(map[string]bool, len(types))}
+	for _, name := range types {
+		g.requested[name] = true
+	}
+
+	for _, name := range types {
+		decl, ok := pkg.types[name]
+		if !ok {
+			return nil, fmt.Errorf("type %s not found in package %s", name, pkg.name)
+		}
+		if decl.spec.TypeParams != nil {
+			return nil, fmt.Errorf("type %s: generic types aren't supported", name)
+		}
+
+		var err error
+		switch t := decl.spec.Type.(type) {
+		case *ast.StructType:
+			err = g.writeObject(name, t, decl)
+		case *ast.ArrayType:
+			err = g.writeArray(name, t, decl)
+		default:
+			err = fmt.Errorf("type %s is neither a struct nor a slice or an array", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by logmarshal -type %s; DO NOT EDIT.\n\n", strings.Join(types, ","))
+	fmt.Fprintf(&src, "package %s\n\nimport (\n", pkg.name)
+	if g.usesLogger {
+		src.WriteString("\t\"github.com/catalystgo/logger/logger\"\n")
+	}
+	src.WriteString("\t\"go.uber.org/zap/zapcore\"\n)\n")
+	src.Write(g.buf.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return formatted, nil
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) writeObject(name string, t *ast.StructType, decl *typeDecl) error {
+	recv := receiverName(name)
+	g.vars = 0
+	g.printf("\nfunc (%s %s) MarshalLogObject(enc zapcore.ObjectEncoder) error {\n", recv, name)
+	if err := g.writeFields(t, decl.imports, recv, 0); err != nil {
+		return fmt.Errorf("type %s: %w", name, err)
+	}
+	g.printf("return nil\n}\n")
+	return nil
+}
+
+func (g *generator) writeArray(name string, t *ast.ArrayType, decl *typeDecl) error {
+	recv := receiverName(name)
+	g.vars = 0
+	elem := g.resolve(t.Elt, decl.imports, 0)
+	v := g.newVar("e")
+
+	g.printf("\nfunc (%s %s) MarshalLogArray(enc zapcore.ArrayEncoder) error {\n", recv, name)
+	g.printf("for _, %s := range %s {\n", v, recv)
+	g.appendValue("enc", v, elem)
+	g.printf("}\nreturn nil\n}\n")
+	return nil
+}
+
+// writeFields Write the statements adding the fields of the struct accessed by the selector,
+// following encoding the struct fields in the logger package
+func (g *generator) writeFields(t *ast.StructType, imports map[string]string, selector string, depth int) error {
+	if depth >= maxResolveDepth {
+		return fmt.Errorf("embedded structs nested too deeply at %s", selector)
+	}
+
+	for _, f := range t.Fields.List {
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			raw, _ := strconv.Unquote(f.Tag.Value)
+			tag = reflect.StructTag(raw)
+		}
+
+		logTag, hasLogTag := tag.Lookup("log")
+		if logTag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(logTag, ",")
+
+		if !hasLogTag {
+			jsonTag := tag.Get("json")
+			if jsonTag == "-" {
+				continue
+			}
+			name, _, _ = strings.Cut(jsonTag, ",")
+		}
+
+		fieldNames := make([]string, 0, len(f.Names))
+		for _, n := range f.Names {
+			fieldNames = append(fieldNames, n.Name)
+		}
+
+		if len(f.Names) == 0 {
+			typeName, ptr := embeddedType(f.Type)
+			fieldSelector := selector + "." + typeName
+
+			// embedded structs without a name are inlined
+			if name == "" {
+				done, err := g.writeEmbedded(f.Type, imports, fieldSelector, ptr, depth)
+				if err != nil {
+					return err
+				}
+				if done {
+					continue
+				}
+			}
+			fieldNames = append(fieldNames, typeName)
+		}
+
+		for _, fieldName := range fieldNames {
+			if !ast.IsExported(fieldName) {
+				continue
+			}
+
+			key := name
+			if key == "" {
+				key = fieldName
+			}
+			g.writeField(strconv.Quote(key), selector+"."+fieldName, opts, g.resolve(f.Type, imports, 0))
+		}
+	}
+	return nil
+}
+
+// writeEmbedded Write the statements inlining the embedded struct, false if it isn't a struct
+func (g *generator) writeEmbedded(typ ast.Expr, imports map[string]string, selector string, ptr bool, depth int) (bool, error) {
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+
+	var st *ast.StructType
+	var stImports map[string]string
+	switch t := typ.(type) {
+	case *ast.Ident:
+		decl, ok := g.pkg.types[t.Name]
+		if !ok {
+			return false, nil
+		}
+		if st, ok = decl.spec.Type.(*ast.StructType); !ok {
+			return false, nil
+		}
+		stImports = decl.imports
+	case *ast.SelectorExpr:
+		// the fields of the structs of other packages are only known at runtime
+		addr := selector
+		if !ptr {
+			addr = "&" + selector
+		}
+		g.usesLogger = true
+		g.printf("if err := logger.MarshalObject(%s).MarshalLogObject(enc); err != nil {\nreturn err\n}\n", addr)
+		return true, nil
+	default:
+		return false, nil
+	}
+
+	// the fields of a nil embedded pointer are omitted
+	if ptr {
+		g.printf("if %s != nil {\n", selector)
+	}
+	if err := g.writeFields(st, stImports, selector, depth+1); err != nil {
+		return false, err
+	}
+	if ptr {
+		g.printf("}\n")
+	}
+	return true, nil
+}
+
+func (g *generator) writeField(key, v, opts string, t valueType) {
+	var omitEmpty, redact, hash bool
+	for _, opt := range strings.Split(opts, ",") {
+		switch opt {
+		case "omitempty":
+			omitEmpty = true
+		case "redact":
+			redact = true
+		case "hash":
+			hash = true
+		}
+	}
+
+	if omitEmpty {
+		// the checks of the nilable values skip the nil ones
+		t.nilable = false
+		if t.empty == "" {
+			// structs & types of other packages are checked at runtime like the logger does
+			g.usesLogger = true
+			g.printf("if !logger.IsEmpty(%s) {\n", v)
+		} else {
+			g.printf("if "+t.empty+" {\n", v)
+		}
+	}
+	switch {
+	case redact:
+		g.usesLogger = true
+		g.printf("enc.AddString(%s, logger.DefaultRedactMask)\n", key)
+	case hash:
+		g.usesLogger = true
+		g.printf("enc.AddString(%s, logger.Hash(%s))\n", key, v)
+	default:
+		g.addValue(key, v, t)
+	}
+	if omitEmpty {
+		g.printf("}\n")
+	}
+}
+
+// addValue Write the statements adding the value to the object encoder enc
+func (g *generator) addValue(key, v string, t valueType) {
+	if t.nilable {
+		g.printf("if %s == nil {\n", v)
+		g.printf("if err := enc.AddReflected(%s, nil); err != nil {\nreturn err\n}\n", key)
+		g.printf("} else {\n")
+		defer g.printf("}\n")
+	}
+
+	switch t.kind {
+	case kindBasic:
+		g.printf("enc.Add%s(%s, %s)\n", basicMethods[t.basic], key, t.convert(v))
+	case kindBytes:
+		g.printf("enc.AddBinary(%s, %s)\n", key, v)
+	case kindTime:
+		g.printf("enc.AddTime(%s, %s)\n", key, v)
+	case kindDuration:
+		g.printf("enc.AddDuration(%s, %s)\n", key, v)
+	case kindError:
+		g.printf("enc.AddString(%s, %s.Error())\n", key, v)
+	case kindObject:
+		g.printf("if err := enc.AddObject(%s, %s); err != nil {\nreturn err\n}\n", key, v)
+	case kindArray:
+		g.printf("if err := enc.AddArray(%s, %s); err != nil {\nreturn err\n}\n", key, v)
+	case kindPointer:
+		g.addValue(key, t.elem.deref(v), t.elem.pointed())
+	case kindSlice:
+		g.printf("if err := enc.AddArray(%s, ", key)
+		g.writeArrayFunc(v, *t.elem)
+		g.printf("); err != nil {\nreturn err\n}\n")
+	default:
+		g.usesLogger = true
+		g.printf("if err := logger.AddTagged(enc, %s, %s); err != nil {\nreturn err\n}\n", key, v)
+	}
+}
+
+// appendValue Write the statements appending the value to the array encoder
+func (g *generator) appendValue(arr, v string, t valueType) {
+	if t.nilable {
+		g.printf("if %s == nil {\n", v)
+		g.printf("if err := %s.AppendReflected(nil); err != nil {\nreturn err\n}\n", arr)
+		g.printf("} else {\n")
+		defer g.printf("}\n")
+	}
+
+	switch t.kind {
+	case kindBasic:
+		g.printf("%s.Append%s(%s)\n", arr, basicMethods[t.basic], t.convert(v))
+	case kindTime:
+		g.printf("%s.AppendTime(%s)\n", arr, v)
+	case kindDuration:
+		g.printf("%s.AppendDuration(%s)\n", arr, v)
+	case kindError:
+		g.printf("%s.AppendString(%s.Error())\n", arr, v)
+	case kindObject:
+		g.printf("if err := %s.AppendObject(%s); err != nil {\nreturn err\n}\n", arr, v)
+	case kindArray:
+		g.printf("if err := %s.AppendArray(%s); err != nil {\nreturn err\n}\n", arr, v)
+	case kindPointer:
+		g.appendValue(arr, t.elem.deref(v), t.elem.pointed())
+	case kindSlice:
+		g.printf("if err := %s.AppendArray(", arr)
+		g.writeArrayFunc(v, *t.elem)
+		g.printf("); err != nil {\nreturn err\n}\n")
+	default:
+		// including the byte slices, written as base64 like encoding/json
+		g.usesLogger = true
+		g.printf("if err := logger.AppendTagged(%s, %s); err != nil {\nreturn err\n}\n", arr, v)
+	}
+}
+
+// writeArrayFunc Write an ArrayMarshalerFunc appending the elements of the slice
+func (g *generator) writeArrayFunc(v string, elem valueType) {
+	arr, e := g.newVar("arr"), g.newVar("e")
+	g.printf("zapcore.ArrayMarshalerFunc(func(%s zapcore.ArrayEncoder) error {\n", arr)
+	g.printf("for _, %s := range %s {\n", e, v)
+	g.appendValue(arr, e, elem)
+	g.printf("}\nreturn nil\n})")
+}
+
+func (g *generator) newVar(prefix string) string {
+	g.vars++
+	return prefix + strconv.Itoa(g.vars)
+}
+
+// resolve Get how the value of the type is encoded
+func (g *generator) resolve(expr ast.Expr, imports map[string]string, depth int) valueType {
+	if depth >= maxResolveDepth {
+		return valueType{kind: kindTagged}
+	}
+
+	switch t := expr.(type) {
+	case *ast.ParenExpr:
+		return g.resolve(t.X, imports, depth+1)
+	case *ast.Ident:
+		if _, ok := g.pkg.types[t.Name]; ok {
+			return g.resolveNamed(t.Name, depth)
+		}
+		if method, ok := basicMethods[t.Name]; ok {
+			basic := strings.ToLower(method)
+			return valueType{kind: kindBasic, basic: basic, empty: basicEmpty(basic)}
+		}
+		switch t.Name {
+		case "error":
+			return valueType{kind: kindError, nilable: true, empty: "%s != nil"}
+		case "any":
+			return valueType{kind: kindTagged, empty: "%s != nil"}
+		}
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok && imports[pkg.Name] == "time" {
+			switch t.Sel.Name {
+			case "Time":
+				return valueType{kind: kindTime, empty: "!%s.IsZero()"}
+			case "Duration":
+				return valueType{kind: kindDuration, empty: "%s != 0"}
+			}
+		}
+	case *ast.StarExpr:
+		elem := g.resolve(t.X, imports, depth+1)
+		if elem.kind == kindTagged {
+			return valueType{kind: kindTagged, empty: "%s != nil"}
+		}
+		return valueType{kind: kindPointer, nilable: true, empty: "%s != nil", elem: &elem}
+	case *ast.ArrayType:
+		elem := g.resolve(t.Elt, imports, depth+1)
+		if elem.kind == kindBasic && elem.basic == "uint8" && !elem.named {
+			if t.Len != nil {
+				// encoded as a JSON array like encoding/json does
+				return valueType{kind: kindTagged, empty: "len(%s) != 0"}
+			}
+			return valueType{kind: kindBytes, nilable: true, empty: "len(%s) != 0"}
+		}
+		return valueType{kind: kindSlice, nilable: t.Len == nil, empty: "len(%s) != 0", elem: &elem}
+	case *ast.MapType:
+		return valueType{kind: kindTagged, empty: "len(%s) != 0"}
+	case *ast.InterfaceType, *ast.FuncType, *ast.ChanType:
+		return valueType{kind: kindTagged, empty: "%s != nil"}
+	}
+	return valueType{kind: kindTagged}
+}
+
+// resolveNamed Get how the value of the type declared in the package is encoded
+func (g *generator) resolveNamed(name string, depth int) valueType {
+	decl := g.pkg.types[name]
+	if decl.spec.Assign.IsValid() {
+		return g.resolve(decl.spec.Type, decl.imports, depth+1)
+	}
+	if decl.spec.TypeParams != nil {
+		return valueType{kind: kindTagged}
+	}
+
+	underlying := g.resolve(decl.spec.Type, decl.imports, depth+1)
+	nilable := underlying.nilable || underlying.kind == kindBytes
+	switch {
+	case g.hasMarshalLogObject(name, decl):
+		return valueType{kind: kindObject, nilable: nilable, empty: underlying.empty}
+	case g.hasMarshalLogArray(name, decl):
+		return valueType{kind: kindArray, nilable: nilable, empty: underlying.empty}
+	case g.pkg.hasMethod(name, "MarshalLogObject", false),
+		g.pkg.hasMethod(name, "MarshalLogArray", false),
+		g.pkg.hasMethod(name, "Error", false),
+		g.pkg.hasMethod(name, "MarshalJSON", false):
+		// checked at runtime like the logger does
+		return valueType{kind: kindTagged, empty: underlying.empty}
+	case underlying.kind == kindBasic:
+		underlying.named = true
+		return underlying
+	}
+	return valueType{kind: kindTagged, empty: underlying.empty}
+}
+
+func (g *generator) hasMarshalLogObject(name string, decl *typeDecl) bool {
+	if g.requested[name] {
+		_, ok := decl.spec.Type.(*ast.StructType)
+		return ok
+	}
+	return g.pkg.hasMethod(name, "MarshalLogObject", true)
+}
+
+func (g *generator) hasMarshalLogArray(name string, decl *typeDecl) bool {
+	if g.requested[name] {
+		_, ok := decl.spec.Type.(*ast.ArrayType)
+		return ok
+	}
+	return g.pkg.hasMethod(name, "MarshalLogArray", true)
+}
+
+// convert Get the value converted to the basic type if it's a named one
+func (t valueType) convert(v string) string {
+	if t.named {
+		return t.basic + "(" + v + ")"
+	}
+	return v
+}
+
+// deref Get the value pointed by the pointer v, the marshalers are used through the pointer
+func (t valueType) deref(v string) string {
+	if t.kind == kindObject || t.kind == kindArray {
+		return v
+	}
+	return "*" + v
+}
+
+// pointed Get the type of the value pointed by a non nil pointer,
+// a marshaler used through the pointer isn't nil
+func (t valueType) pointed() valueType {
+	if t.kind == kindObject || t.kind == kindArray {
+		t.nilable = false
+	}
+	return t
+}
+
+func basicEmpty(basic string) string {
+	switch basic {
+	case "string":
+		return `%s != ""`
+	case "bool":
+		return "%s"
+	}
+	return "%s != 0"
+}
+
+// embeddedType Get the field name of the embedded type, true if it's a pointer
+func embeddedType(expr ast.Expr) (string, bool) {
+	ptr := false
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr, ptr = star.X, true
+	}
+
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name, ptr
+	case *ast.SelectorExpr:
+		return t.Sel.Name, ptr
+	case *ast.IndexExpr:
+		name, _ := embeddedType(t.X)
+		return name, ptr
+	case *ast.IndexListExpr:
+		name, _ := embeddedType(t.X)
+		return name, ptr
+	}
+	return "", ptr
+}
+
+// receiverName Get the receiver name of the type methods, its lower cased first letter
+func receiverName(typeName string) string {
+	for _, r := range typeName {
+		return string(unicode.ToLower(r))
+	}
+	return "v"
+}
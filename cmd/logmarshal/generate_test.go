@@ -0,0 +1,141 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateExample(t *testing.T) {
+	t.Parallel()
+
+	const dir = "internal/example"
+	output := filepath.Join(t.TempDir(), "order_logmarshal.go")
+	require.NoError(t, run(dir, []string{"Order", "Item", "Items", "Address"}, output))
+
+	expected, err := os.ReadFile(filepath.Join(dir, "order_logmarshal.go"))
+	require.NoError(t, err)
+	generated, err := os.ReadFile(output)
+	require.NoError(t, err)
+
+	// run go generate in internal/example after changing the generator
+	require.Equal(t, string(expected), string(generated))
+}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		src      string
+		types    []string
+		contains []string
+		err      string
+	}{
+		{
+			name: "nested slices & pointers",
+			src: `package p
+
+type ID int64
+
+type Group struct {
+	Members [][]*ID ` + "`log:\"members\"`" + `
+}`,
+			types: []string{"Group"},
+			contains: []string{
+				`if err := enc.AddArray("members", zapcore.ArrayMarshalerFunc(func(arr1 zapcore.ArrayEncoder) error {`,
+				`arr3.AppendInt64(int64(*e4))`,
+			},
+		},
+		{
+			name: "hand written marshalers & foreign types",
+			src: `package p
+
+import (
+	"net/url"
+	tm "time"
+)
+
+type Labels map[string]string
+
+func (l Labels) MarshalLogObject(enc zapcore.ObjectEncoder) error { return nil }
+
+type Event struct {
+	*url.URL
+	At      tm.Time ` + "`log:\"at,omitempty\"`" + `
+	Labels  Labels  ` + "`log:\"labels\"`" + `
+	Payload []byte  ` + "`log:\"payload\"`" + `
+}`,
+			types: []string{"Event"},
+			contains: []string{
+				`if err := logger.MarshalObject(e.URL).MarshalLogObject(enc); err != nil {`,
+				`if !e.At.IsZero() {`,
+				`if err := enc.AddObject("labels", e.Labels); err != nil {`,
+				`enc.AddBinary("payload", e.Payload)`,
+			},
+		},
+		{
+			name: "omitempty structs",
+			src: `package p
+
+import "net/url"
+
+type Point struct{ X, Y int }
+
+type Shape struct {
+	Center Point   ` + "`log:\"center,omitempty\"`" + `
+	Link   url.URL ` + "`log:\"link,omitempty\"`" + `
+}`,
+			types: []string{"Shape", "Point"},
+			contains: []string{
+				`if !logger.IsEmpty(s.Center) {`,
+				`if err := enc.AddObject("center", s.Center); err != nil {`,
+				`if !logger.IsEmpty(s.Link) {`,
+			},
+		},
+		{
+			name:  "unknown type",
+			src:   "package p\n",
+			types: []string{"Order"},
+			err:   "type Order not found in package p",
+		},
+		{
+			name:  "generic type",
+			src:   "package p\n\ntype Page[T any] struct{ Items []T }\n",
+			types: []string{"Page"},
+			err:   "type Page: generic types aren't supported",
+		},
+		{
+			name:  "map type",
+			src:   "package p\n\ntype Labels map[string]string\n",
+			types: []string{"Labels"},
+			err:   "type Labels is neither a struct nor a slice or an array",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "p.go"), []byte(tc.src), 0o644))
+
+			pkg, err := parsePackage(dir, "")
+			require.NoError(t, err)
+
+			src, err := generate(pkg, tc.types)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			for _, s := range tc.contains {
+				require.Contains(t, string(src), s)
+			}
+		})
+	}
+}
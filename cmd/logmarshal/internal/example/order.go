@@ -0,0 +1,53 @@
+// Package example Types whose log methods are generated by logmarshal,
+// checking the generated code encodes them like logger.MarshalObject
+package example
+
+import "time"
+
+//go:generate go run github.com/catalystgo/logger/cmd/logmarshal -type Order,Item,Items,Address
+
+// Status State of an order
+type Status string
+
+// Audit Change tracking fields shared by the entities
+type Audit struct {
+	CreatedAt time.Time `log:"created_at"`
+	UpdatedBy string    `log:"updated_by,omitempty"`
+}
+
+// Order Order placed by a customer
+type Order struct {
+	Audit
+	ID       int64             `log:"id"`
+	Status   Status            `log:"status"`
+	Coupon   string            `log:"coupon,omitempty"`
+	Card     string            `log:"card,redact"`
+	Email    string            `log:"email,hash"`
+	Secret   string            `log:"-"`
+	Items    Items             `log:"items"`
+	Tags     []string          `log:"tags,omitempty"`
+	Parent   *Order            `log:"parent"`
+	Timeout  time.Duration     `json:"timeout"`
+	Discount *float64          `log:"discount,omitempty"`
+	Meta     map[string]string `log:"meta,omitempty"`
+	Shipping Address           `log:"shipping,omitempty"`
+	Err      error             `log:"error"`
+	Note     string
+	internal string
+}
+
+// Item Line of an order
+type Item struct {
+	SKU   string  `log:"sku"`
+	Qty   int     `log:"qty"`
+	Price float64 `log:"price"`
+}
+
+// Items Lines of an order
+type Items []Item
+
+// Address Shipping address of an order
+type Address struct {
+	City   string `log:"city"`
+	Street string `log:"street"`
+}
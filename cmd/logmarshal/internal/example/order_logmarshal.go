@@ -0,0 +1,95 @@
+// Code generated by logmarshal -type Order,Item,Items,Address; DO NOT EDIT.
+
+package example
+
+import (
+	"github.com/catalystgo/logger/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+func (o Order) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddTime("created_at", o.Audit.CreatedAt)
+	if o.Audit.UpdatedBy != "" {
+		enc.AddString("updated_by", o.Audit.UpdatedBy)
+	}
+	enc.AddInt64("id", o.ID)
+	enc.AddString("status", string(o.Status))
+	if o.Coupon != "" {
+		enc.AddString("coupon", o.Coupon)
+	}
+	enc.AddString("card", logger.DefaultRedactMask)
+	enc.AddString("email", logger.Hash(o.Email))
+	if o.Items == nil {
+		if err := enc.AddReflected("items", nil); err != nil {
+			return err
+		}
+	} else {
+		if err := enc.AddArray("items", o.Items); err != nil {
+			return err
+		}
+	}
+	if len(o.Tags) != 0 {
+		if err := enc.AddArray("tags", zapcore.ArrayMarshalerFunc(func(arr1 zapcore.ArrayEncoder) error {
+			for _, e2 := range o.Tags {
+				arr1.AppendString(e2)
+			}
+			return nil
+		})); err != nil {
+			return err
+		}
+	}
+	if o.Parent == nil {
+		if err := enc.AddReflected("parent", nil); err != nil {
+			return err
+		}
+	} else {
+		if err := enc.AddObject("parent", o.Parent); err != nil {
+			return err
+		}
+	}
+	enc.AddDuration("timeout", o.Timeout)
+	if o.Discount != nil {
+		enc.AddFloat64("discount", *o.Discount)
+	}
+	if len(o.Meta) != 0 {
+		if err := logger.AddTagged(enc, "meta", o.Meta); err != nil {
+			return err
+		}
+	}
+	if !logger.IsEmpty(o.Shipping) {
+		if err := enc.AddObject("shipping", o.Shipping); err != nil {
+			return err
+		}
+	}
+	if o.Err == nil {
+		if err := enc.AddReflected("error", nil); err != nil {
+			return err
+		}
+	} else {
+		enc.AddString("error", o.Err.Error())
+	}
+	enc.AddString("Note", o.Note)
+	return nil
+}
+
+func (i Item) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("sku", i.SKU)
+	enc.AddInt("qty", i.Qty)
+	enc.AddFloat64("price", i.Price)
+	return nil
+}
+
+func (i Items) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, e1 := range i {
+		if err := enc.AppendObject(e1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a Address) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("city", a.City)
+	enc.AddString("street", a.Street)
+	return nil
+}
@@ -0,0 +1,90 @@
+package example
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/catalystgo/logger/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestGeneratedMarshalLogObject(t *testing.T) {
+	t.Parallel()
+
+	discount := 0.5
+	createdAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		order    Order
+		expected string
+	}{
+		{
+			name: "full",
+			order: Order{
+				Audit:    Audit{CreatedAt: createdAt, UpdatedBy: "admin"},
+				ID:       42,
+				Status:   "paid",
+				Coupon:   "SPRING",
+				Card:     "4111111111111111",
+				Email:    "john@example.com",
+				Secret:   "secret",
+				Items:    Items{{SKU: "apple", Qty: 3, Price: 1.5}},
+				Tags:     []string{"gift"},
+				Parent:   &Order{ID: 41, Items: Items{}},
+				Timeout:  time.Second,
+				Discount: &discount,
+				Meta:     map[string]string{"source": "web"},
+				Shipping: Address{City: "Paris", Street: "Rue de Rivoli"},
+				Err:      errors.New("card declined"),
+				Note:     "leave at the door",
+				internal: "internal",
+			},
+			expected: `{"order":{
+				"created_at":"2024-03-01T12:00:00.000Z","updated_by":"admin",
+				"id":42,"status":"paid","coupon":"SPRING","card":"[REDACTED]","email":"` + logger.Hash("john@example.com") + `",
+				"items":[{"sku":"apple","qty":3,"price":1.5}],"tags":["gift"],
+				"parent":{"created_at":"0001-01-01T00:00:00.000Z","id":41,"status":"","card":"[REDACTED]","email":"` + logger.Hash("") + `",
+					"items":[],"parent":null,"timeout":0,"error":null,"Note":""},
+				"timeout":1,"discount":0.5,"meta":{"source":"web"},"shipping":{"city":"Paris","street":"Rue de Rivoli"},"error":"card declined","Note":"leave at the door"
+			}}`,
+		},
+		{
+			name:  "empty",
+			order: Order{},
+			expected: `{"order":{
+				"created_at":"0001-01-01T00:00:00.000Z","id":0,"status":"","card":"[REDACTED]","email":"` + logger.Hash("") + `",
+				"items":null,"parent":null,"timeout":0,"error":null,"Note":""
+			}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			generated := encode(t, zap.Object("order", tc.order))
+			require.JSONEq(t, tc.expected, generated)
+
+			// the generated code encodes the order like the reflection does
+			require.JSONEq(t, encode(t, logger.Object("order", tc.order)), generated)
+		})
+	}
+}
+
+func encode(t *testing.T, field zap.Field) string {
+	t.Helper()
+
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.EncodeTime = zapcore.ISO8601TimeEncoder
+	cfg.EncodeDuration = zapcore.SecondsDurationEncoder
+	cfg.TimeKey, cfg.LevelKey, cfg.MessageKey = "", "", ""
+
+	buf, err := zapcore.NewJSONEncoder(cfg).EncodeEntry(zapcore.Entry{}, []zap.Field{field})
+	require.NoError(t, err)
+	return buf.String()
+}
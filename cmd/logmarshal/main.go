@@ -0,0 +1,62 @@
+// Command logmarshal generates MarshalLogObject & MarshalLogArray methods encoding
+// the types like logger.MarshalObject does, without reflection on the hot path:
+//
+//	//go:generate go run github.com/catalystgo/logger/cmd/logmarshal -type Order,Items
+//
+// The structs get a MarshalLogObject method honouring the `log:"name,omitempty,redact,hash"`
+// & `log:"-"` tags (a field without a `log` tag is named after its `json` tag or its name),
+// the slice & array types get a MarshalLogArray one.
+// The fields whose type can't be resolved from the package sources (e.g. the maps, the
+// interfaces or the types of other packages) are encoded with logger.AddTagged.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of the type names, required")
+	output := flag.String("output", "", "output file name, default <dir>/<type>_logmarshal.go")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: logmarshal -type T[,T...] [-output file] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	types := strings.Split(*typeNames, ",")
+	if *output == "" {
+		*output = filepath.Join(dir, strings.ToLower(types[0])+"_logmarshal.go")
+	}
+
+	if err := run(dir, types, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "logmarshal: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir string, types []string, output string) error {
+	pkg, err := parsePackage(dir, filepath.Base(output))
+	if err != nil {
+		return err
+	}
+
+	src, err := generate(pkg, types)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(output, src, 0o644)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// pkgInfo Declarations of the parsed package
+type pkgInfo struct {
+	name  string
+	types map[string]*typeDecl
+	// methods Method names of the types declared in the package
+	methods map[string][]method
+}
+
+type typeDecl struct {
+	spec *ast.TypeSpec
+	// imports Import paths of the file declaring the type by their name in the file
+	imports map[string]string
+}
+
+type method struct {
+	name        string
+	ptrReceiver bool
+}
+
+// parsePackage Parse the declarations of the package in the directory,
+// the test files & the skipped one (the output of a previous run) are ignored
+func parsePackage(dir, skip string) (*pkgInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	pkg := &pkgInfo{
+		types:   make(map[string]*typeDecl),
+		methods: make(map[string][]method),
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || name == skip {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+
+		if pkg.name == "" {
+			pkg.name = f.Name.Name
+		} else if pkg.name != f.Name.Name {
+			return nil, fmt.Errorf("%s: found packages %s and %s", dir, pkg.name, f.Name.Name)
+		}
+
+		pkg.addFile(f)
+	}
+
+	if pkg.name == "" {
+		return nil, fmt.Errorf("%s: no Go files", dir)
+	}
+	return pkg, nil
+}
+
+func (p *pkgInfo) addFile(f *ast.File) {
+	imports := make(map[string]string, len(f.Imports))
+	for _, spec := range f.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = importPath
+	}
+
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				ts := spec.(*ast.TypeSpec)
+				p.types[ts.Name.Name] = &typeDecl{spec: ts, imports: imports}
+			}
+		case *ast.FuncDecl:
+			if d.Recv == nil || len(d.Recv.List) == 0 {
+				continue
+			}
+
+			recv, ptr := receiverType(d.Recv.List[0].Type)
+			if recv != "" {
+				p.methods[recv] = append(p.methods[recv], method{name: d.Name.Name, ptrReceiver: ptr})
+			}
+		}
+	}
+}
+
+// receiverType Get the name of the receiver type, true if it's a pointer
+func receiverType(expr ast.Expr) (string, bool) {
+	ptr := false
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr, ptr = star.X, true
+	}
+
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name, ptr
+	case *ast.IndexExpr:
+		name, _ := receiverType(t.X)
+		return name, ptr
+	case *ast.IndexListExpr:
+		name, _ := receiverType(t.X)
+		return name, ptr
+	}
+	return "", ptr
+}
+
+// hasMethod Check whether the type has the method, with a value receiver if valueOnly
+func (p *pkgInfo) hasMethod(typeName, name string, valueOnly bool) bool {
+	for _, m := range p.methods[typeName] {
+		if m.name == name && (!valueOnly || !m.ptrReceiver) {
+			return true
+		}
+	}
+	return false
+}
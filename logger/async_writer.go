@@ -0,0 +1,316 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+// OverflowPolicy What an AsyncWriter does with an entry when its queue is full
+type OverflowPolicy int
+
+const (
+	// OverflowBlock wait for the queue to have room, no entry is lost
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDrop drop the entry and count it, logging never blocks
+	OverflowDrop
+)
+
+const (
+	defaultAsyncQueueSize     = 1024
+	defaultAsyncBufferSize    = 256 << 10
+	defaultAsyncFlushInterval = time.Second
+)
+
+var errAsyncWriterStopping = errors.New("async writer: still writing the queued entries after shutdown")
+
+var (
+	asyncWritersMu sync.Mutex
+	asyncWriters   = make(map[*AsyncWriter]struct{})
+)
+
+// AsyncWriterConfig Queue & buffering of an AsyncWriter, zero values use the defaults
+type AsyncWriterConfig struct {
+	// QueueSize number of entries waiting to be written (default 1024)
+	QueueSize int
+	// OnFull policy applied when the queue is full (default OverflowBlock)
+	OnFull OverflowPolicy
+	// BufferSize bytes buffered before being written to the sink (default 256KB)
+	BufferSize int
+	// FlushInterval the buffer is written to the sink at least that often (default 1s)
+	FlushInterval time.Duration
+}
+
+// AsyncWriter zapcore.WriteSyncer handing the entries to a goroutine
+// writing them to the sink, so logging doesn't wait for the sink.
+//
+// The writers are registered until shut down, Sync & Shutdown flush all of them:
+//
+//	logger.SetLogger(logger.NewWithSink(nil, logger.NewAsyncWriter(os.Stdout, logger.AsyncWriterConfig{})))
+//	defer logger.Shutdown(ctx)
+type AsyncWriter struct {
+	sink   zapcore.WriteSyncer
+	onFull OverflowPolicy
+
+	queue   chan []byte
+	syncs   chan chan error
+	stop    chan struct{}
+	stopped chan struct{}
+
+	// closed Set once Shutdown is called, the entries are then written to the sink directly
+	closed   atomic.Bool
+	stopOnce sync.Once
+	// mu Held for reading by the writes queuing an entry, the writer goroutine
+	// takes it for writing once stopped so none of them is left in the queue
+	mu sync.RWMutex
+	// directMu Serializes the writes made straight to the sink once closed
+	directMu sync.Mutex
+
+	dropped atomic.Uint64
+}
+
+var _ zapcore.WriteSyncer = (*AsyncWriter)(nil)
+
+// NewAsyncWriter create a new registered async writer to the sink
+func NewAsyncWriter(sink io.Writer, cfg AsyncWriterConfig) *AsyncWriter {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultAsyncQueueSize
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaultAsyncBufferSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultAsyncFlushInterval
+	}
+
+	w := &AsyncWriter{
+		sink:    zapcore.AddSync(sink),
+		onFull:  cfg.OnFull,
+		queue:   make(chan []byte, cfg.QueueSize),
+		syncs:   make(chan chan error),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go w.run(cfg.BufferSize, cfg.FlushInterval)
+
+	asyncWritersMu.Lock()
+	asyncWriters[w] = struct{}{}
+	asyncWritersMu.Unlock()
+
+	return w
+}
+
+// Write Queue a copy of the entry, once shut down it's written to the sink directly
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	if w.enqueue(p) {
+		return len(p), nil
+	}
+	return w.writeDirect(p)
+}
+
+// enqueue Queue a copy of the entry, false if the writer is shut down
+func (w *AsyncWriter) enqueue(p []byte) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed.Load() {
+		return false
+	}
+
+	entry := make([]byte, len(p))
+	copy(entry, p)
+
+	if w.onFull == OverflowDrop {
+		select {
+		case w.queue <- entry:
+		default:
+			w.dropped.Add(1)
+		}
+		return true
+	}
+
+	// a full queue doesn't hold up Shutdown
+	select {
+	case w.queue <- entry:
+		return true
+	case <-w.stop:
+		return false
+	}
+}
+
+// Sync Write the queued entries & sync the sink, it fails without waiting
+// if the writer is shut down but still writing the queued entries
+func (w *AsyncWriter) Sync() error {
+	if !w.closed.Load() {
+		reply := make(chan error, 1)
+		select {
+		case w.syncs <- reply:
+			return <-reply
+		case <-w.stopped:
+		}
+	}
+
+	select {
+	case <-w.stopped:
+	default:
+		return errAsyncWriterStopping
+	}
+
+	w.directMu.Lock()
+	defer w.directMu.Unlock()
+	return w.sink.Sync()
+}
+
+// Shutdown Flush the queued entries and stop the writer goroutine,
+// the entries written afterwards go straight to the sink.
+// It returns the context error if the deadline expires before the queue is flushed.
+func (w *AsyncWriter) Shutdown(ctx context.Context) error {
+	w.stopOnce.Do(func() {
+		w.closed.Store(true)
+		close(w.stop)
+	})
+
+	asyncWritersMu.Lock()
+	delete(asyncWriters, w)
+	asyncWritersMu.Unlock()
+
+	select {
+	case <-w.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Dropped Get the number of entries dropped because the queue was full
+func (w *AsyncWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+func (w *AsyncWriter) writeDirect(p []byte) (int, error) {
+	<-w.stopped
+
+	w.directMu.Lock()
+	defer w.directMu.Unlock()
+	return w.sink.Write(p)
+}
+
+func (w *AsyncWriter) run(bufferSize int, flushInterval time.Duration) {
+	defer close(w.stopped)
+
+	buf := bufio.NewWriterSize(w.sink, bufferSize)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	report := func(err error) {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "logger: async writer: %v\n", err)
+		}
+	}
+
+	// drain Write the entries queued so far to the buffer
+	drain := func() {
+		for {
+			select {
+			case entry := <-w.queue:
+				_, err := buf.Write(entry)
+				report(err)
+			default:
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case entry := <-w.queue:
+			_, err := buf.Write(entry)
+			report(err)
+
+		case <-ticker.C:
+			report(buf.Flush())
+
+		case reply := <-w.syncs:
+			drain()
+			reply <- multierr.Append(buf.Flush(), w.sink.Sync())
+
+		case <-w.stop:
+			// the writes queuing an entry are done once the lock is acquired
+			w.mu.Lock()
+			w.mu.Unlock()
+			drain()
+			report(buf.Flush())
+			report(w.sink.Sync())
+			return
+		}
+	}
+}
+
+// Sync Flush the global logger and every registered AsyncWriter
+func Sync() error {
+	err := ignoreSyncError(Logger().Sync())
+	for _, w := range registeredAsyncWriters() {
+		err = multierr.Append(err, ignoreSyncError(w.Sync()))
+	}
+	return err
+}
+
+// Shutdown Flush & stop every registered AsyncWriter, then sync the global logger.
+// It returns the context error if the deadline expires before they are flushed.
+func Shutdown(ctx context.Context) error {
+	writers := registeredAsyncWriters()
+
+	errs := make([]error, len(writers))
+	var wg sync.WaitGroup
+	for i, w := range writers {
+		wg.Add(1)
+		go func(i int, w *AsyncWriter) {
+			defer wg.Done()
+			errs[i] = w.Shutdown(ctx)
+		}(i, w)
+	}
+	wg.Wait()
+
+	if err := multierr.Combine(errs...); err != nil {
+		return err
+	}
+	return ignoreSyncError(Logger().Sync())
+}
+
+// ignoreSyncError Ignore the errors of the terminals & pipes which can't be synced (e.g. stdout)
+func ignoreSyncError(err error) error {
+	if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTTY) {
+		return nil
+	}
+	return err
+}
+
+// DroppedEntries Get the number of entries dropped by the registered AsyncWriters
+func DroppedEntries() uint64 {
+	var dropped uint64
+	for _, w := range registeredAsyncWriters() {
+		dropped += w.Dropped()
+	}
+	return dropped
+}
+
+func registeredAsyncWriters() []*AsyncWriter {
+	asyncWritersMu.Lock()
+	defer asyncWritersMu.Unlock()
+
+	writers := make([]*AsyncWriter, 0, len(asyncWriters))
+	for w := range asyncWriters {
+		writers = append(writers, w)
+	}
+	return writers
+}
@@ -0,0 +1,273 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// testSink Sink recording the writes, they block while the sink is paused
+type testSink struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	syncs  int
+	paused chan struct{}
+}
+
+func newTestSink() *testSink {
+	return &testSink{}
+}
+
+func (s *testSink) pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = make(chan struct{})
+}
+
+func (s *testSink) resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	close(s.paused)
+	s.paused = nil
+}
+
+func (s *testSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	paused := s.paused
+	s.mu.Unlock()
+
+	if paused != nil {
+		<-paused
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.Write(p)
+}
+
+func (s *testSink) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.syncs++
+	return nil
+}
+
+func (s *testSink) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.String()
+}
+
+func TestAsyncWriterSync(t *testing.T) {
+	t.Parallel()
+
+	sink := newTestSink()
+	w := NewAsyncWriter(sink, AsyncWriterConfig{FlushInterval: time.Hour})
+	t.Cleanup(func() { require.NoError(t, w.Shutdown(context.Background())) })
+
+	for _, line := range []string{"1\n", "2\n", "3\n"} {
+		_, err := w.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.Empty(t, sink.String())
+
+	require.NoError(t, w.Sync())
+	require.Equal(t, "1\n2\n3\n", sink.String())
+	require.Equal(t, 1, sink.syncs)
+}
+
+func TestAsyncWriterFlushInterval(t *testing.T) {
+	t.Parallel()
+
+	sink := newTestSink()
+	w := NewAsyncWriter(sink, AsyncWriterConfig{FlushInterval: 10 * time.Millisecond})
+	t.Cleanup(func() { require.NoError(t, w.Shutdown(context.Background())) })
+
+	_, err := w.Write([]byte("hello world\n"))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return sink.String() == "hello world\n"
+	}, 5*time.Second, 5*time.Millisecond)
+}
+
+func TestAsyncWriterOverflow(t *testing.T) {
+	t.Parallel()
+
+	// the entries are bigger than the buffer so they are written straight to the sink
+	entry := []byte(strings.Repeat("x", 64) + "\n")
+
+	testCases := []struct {
+		name    string
+		policy  OverflowPolicy
+		dropped uint64
+	}{
+		{name: "drop", policy: OverflowDrop, dropped: 3},
+		{name: "block", policy: OverflowBlock, dropped: 0},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			sink := newTestSink()
+			sink.pause()
+			w := NewAsyncWriter(sink, AsyncWriterConfig{QueueSize: 1, BufferSize: 1, OnFull: tc.policy})
+
+			// the first entry blocks the writer goroutine, the second one fills the queue
+			_, err := w.Write(entry)
+			require.NoError(t, err)
+			require.Eventually(t, func() bool { return len(w.queue) == 0 }, 5*time.Second, time.Millisecond)
+			_, err = w.Write(entry)
+			require.NoError(t, err)
+
+			written := make(chan struct{})
+			go func() {
+				defer close(written)
+				for i := 0; i < 3; i++ {
+					_, _ = w.Write(entry)
+				}
+			}()
+
+			if tc.policy == OverflowBlock {
+				select {
+				case <-written:
+					t.Fatal("write didn't block on a full queue")
+				case <-time.After(50 * time.Millisecond):
+				}
+				sink.resume()
+				<-written
+			} else {
+				<-written
+				sink.resume()
+			}
+			require.NoError(t, w.Shutdown(context.Background()))
+
+			require.Equal(t, tc.dropped, w.Dropped())
+			require.Equal(t, 5-int(tc.dropped), strings.Count(sink.String(), "\n"))
+		})
+	}
+}
+
+func TestAsyncWriterShutdown(t *testing.T) {
+	t.Parallel()
+
+	sink := newTestSink()
+	w := NewAsyncWriter(sink, AsyncWriterConfig{FlushInterval: time.Hour})
+
+	_, err := w.Write([]byte("queued\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Shutdown(context.Background()))
+	require.Equal(t, "queued\n", sink.String())
+
+	// the writer is unregistered and writes directly once shut down
+	require.NotContains(t, registeredAsyncWriters(), w)
+	_, err = w.Write([]byte("direct\n"))
+	require.NoError(t, err)
+	require.Equal(t, "queued\ndirect\n", sink.String())
+	require.NoError(t, w.Sync())
+	require.NoError(t, w.Shutdown(context.Background()))
+}
+
+func TestAsyncWriterShutdownDeadline(t *testing.T) {
+	t.Parallel()
+
+	sink := newTestSink()
+	sink.pause()
+	w := NewAsyncWriter(sink, AsyncWriterConfig{BufferSize: 1})
+
+	_, err := w.Write([]byte(strings.Repeat("x", 64)))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, w.Shutdown(ctx), context.DeadlineExceeded)
+
+	sink.resume()
+	require.NoError(t, w.Shutdown(context.Background()))
+	require.Len(t, sink.String(), 64)
+}
+
+func TestAsyncWriterShutdownStuckSink(t *testing.T) {
+	t.Parallel()
+
+	sink := newTestSink()
+	sink.pause()
+	w := NewAsyncWriter(sink, AsyncWriterConfig{QueueSize: 1, BufferSize: 1})
+
+	// the writes fill the queue & block while the sink never returns
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = w.Write([]byte("entry\n"))
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		done <- w.Shutdown(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		t.Fatal("Shutdown didn't honour the deadline")
+	}
+	require.ErrorIs(t, w.Sync(), errAsyncWriterStopping)
+
+	sink.resume()
+	wg.Wait()
+	require.NoError(t, w.Shutdown(context.Background()))
+	require.Equal(t, strings.Repeat("entry\n", 4), sink.String())
+}
+
+func TestAsyncWriterPanicFlushed(t *testing.T) {
+	t.Parallel()
+
+	sink := newTestSink()
+	w := NewAsyncWriter(sink, AsyncWriterConfig{FlushInterval: time.Hour})
+	t.Cleanup(func() { require.NoError(t, w.Shutdown(context.Background())) })
+
+	l := NewWithSink(zapcore.InfoLevel, w)
+	l.Info("before panic")
+	require.Panics(t, func() { l.Panic("panic message") })
+
+	// the entries above error are synced before panicking or exiting
+	require.Contains(t, sink.String(), "before panic")
+	require.Contains(t, sink.String(), "panic message")
+}
+
+func TestShutdown(t *testing.T) {
+	prev := Logger()
+	t.Cleanup(func() { SetLogger(prev) })
+
+	sinks := []*testSink{newTestSink(), newTestSink()}
+	SetLogger(NewWithSink(zapcore.InfoLevel, NewAsyncWriter(sinks[0], AsyncWriterConfig{FlushInterval: time.Hour})))
+	other := NewWithSink(zapcore.InfoLevel, NewAsyncWriter(sinks[1], AsyncWriterConfig{FlushInterval: time.Hour}))
+
+	Info(context.Background(), "global message")
+	other.Info("other message")
+
+	require.NoError(t, Sync())
+	require.Contains(t, sinks[0].String(), "global message")
+	require.Contains(t, sinks[1].String(), "other message")
+
+	Info(context.Background(), "last message")
+	require.Zero(t, DroppedEntries())
+	require.NoError(t, Shutdown(context.Background()))
+	require.Contains(t, sinks[0].String(), "last message")
+	require.Empty(t, registeredAsyncWriters())
+}
@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"context"
+	"sort"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.uber.org/zap"
+)
+
+var currentBaggageFields atomic.Pointer[BaggageFields]
+
+func init() {
+	currentBaggageFields.Store(&BaggageFields{})
+}
+
+// BaggageFields Selects the OpenTelemetry baggage members of the context injected
+// as fields by FromContext & the package functions, the zero value injects none
+type BaggageFields struct {
+	// Members the keys of the injected members, ignored when All is set
+	Members []string
+	// All inject every member of the baggage
+	All bool
+	// Prefix prepended to the member keys, e.g. "baggage."
+	Prefix string
+}
+
+// AllBaggageFields Get the baggage fields injecting every member with the prefix
+func AllBaggageFields(prefix string) BaggageFields {
+	return BaggageFields{All: true, Prefix: prefix}
+}
+
+// SetBaggageFields Set the baggage members injected by all the loggers,
+// safe to call while logging concurrently
+//
+//	logger.SetBaggageFields(logger.BaggageFields{Members: []string{"tenant_id", "user_id"}})
+//
+// The fields added by AddKV & the ones passed by the caller override the members with the same key.
+func SetBaggageFields(fields BaggageFields) {
+	fields.Members = append([]string(nil), fields.Members...)
+	currentBaggageFields.Store(&fields)
+}
+
+// CurrentBaggageFields Get the baggage members injected by all the loggers
+func CurrentBaggageFields() BaggageFields {
+	fields := *currentBaggageFields.Load()
+	fields.Members = append([]string(nil), fields.Members...)
+	return fields
+}
+
+// enabled Whether any member can be injected
+func (f *BaggageFields) enabled() bool {
+	return f.All || len(f.Members) > 0
+}
+
+// Fields Get the fields of the selected members of the baggage,
+// the members are sorted by key when All is set
+func (f BaggageFields) Fields(bag baggage.Baggage) []zap.Field {
+	return f.fields(f.appendMembers(nil, bag))
+}
+
+// baggageMember Member of the baggage selected by the baggage fields
+type baggageMember struct {
+	key, value string
+}
+
+// appendMembers Append the selected members of the baggage to dst
+func (f *BaggageFields) appendMembers(dst []baggageMember, bag baggage.Baggage) []baggageMember {
+	if bag.Len() == 0 {
+		return dst
+	}
+
+	if f.All {
+		members := bag.Members()
+		sort.Slice(members, func(i, j int) bool { return members[i].Key() < members[j].Key() })
+
+		for _, m := range members {
+			dst = append(dst, baggageMember{m.Key(), m.Value()})
+		}
+		return dst
+	}
+
+	for _, key := range f.Members {
+		if m := bag.Member(key); m.Key() != "" {
+			dst = append(dst, baggageMember{key, m.Value()})
+		}
+	}
+	return dst
+}
+
+// fields Get the fields of the members
+func (f *BaggageFields) fields(members []baggageMember) []zap.Field {
+	if len(members) == 0 {
+		return nil
+	}
+
+	fields := make([]zap.Field, len(members))
+	for i, m := range members {
+		fields[i] = zap.String(f.Prefix+m.key, m.value)
+	}
+	return fields
+}
+
+// kvs Get the fields of the members in the form of the AddKV ones
+func (f *BaggageFields) kvs(members []baggageMember) []any {
+	kvs := make([]any, len(members))
+	for i, m := range members {
+		kvs[i] = zap.String(f.Prefix+m.key, m.value)
+	}
+	return kvs
+}
+
+// baggageKvs Get the fields of the context baggage in the form of the AddKV ones
+func baggageKvs(ctx context.Context) []any {
+	config := currentBaggageFields.Load()
+	if !config.enabled() {
+		return nil
+	}
+
+	var buf [8]baggageMember
+	members := config.appendMembers(buf[:0], baggage.FromContext(ctx))
+	if len(members) == 0 {
+		return nil
+	}
+	return config.kvs(members)
+}
+
+// loggerWithBaggage Get the logger with the baggage members & the AddKV fields of the
+// context attached, false if no member is injected. The logger is computed once
+// per logger, AddKV fields & member values (see loggerWithCachedBaggage).
+func loggerWithBaggage(ctx context.Context, l *zap.SugaredLogger) (*zap.SugaredLogger, bool) {
+	config := currentBaggageFields.Load()
+	if !config.enabled() {
+		return l, false
+	}
+
+	var buf [8]baggageMember
+	members := config.appendMembers(buf[:0], baggage.FromContext(ctx))
+	if len(members) == 0 {
+		return l, false
+	}
+	return loggerWithCachedBaggage(l, getKvNode(ctx), config, members), true
+}
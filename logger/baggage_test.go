@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/baggage"
+	"go.uber.org/zap/zapcore"
+)
+
+func testBaggage(t *testing.T, s string) baggage.Baggage {
+	t.Helper()
+
+	bag, err := baggage.Parse(s)
+	require.NoError(t, err)
+	return bag
+}
+
+func TestSetBaggageFields(t *testing.T) {
+	t.Cleanup(func() { SetBaggageFields(BaggageFields{}) })
+
+	buf := bytes.Buffer{}
+	ctx := ToContext(context.Background(), NewWithSink(zapcore.DebugLevel, &buf))
+	ctx = baggage.ContextWithBaggage(ctx, testBaggage(t, "user_id=42,tenant_id=acme,region=eu"))
+
+	testCases := []struct {
+		name     string
+		fields   BaggageFields
+		log      func(ctx context.Context)
+		expected map[string]interface{}
+	}{
+		{
+			name:     "none",
+			fields:   BaggageFields{},
+			log:      func(ctx context.Context) { Info(ctx, "message") },
+			expected: map[string]interface{}{},
+		},
+		{
+			name:   "members",
+			fields: BaggageFields{Members: []string{"tenant_id", "user_id", "missing"}},
+			log:    func(ctx context.Context) { Info(ctx, "message") },
+			expected: map[string]interface{}{
+				"tenant_id": "acme",
+				"user_id":   "42",
+			},
+		},
+		{
+			name:   "all with prefix",
+			fields: AllBaggageFields("baggage."),
+			log:    func(ctx context.Context) { FromContext(ctx).Info("message") },
+			expected: map[string]interface{}{
+				"baggage.region":    "eu",
+				"baggage.tenant_id": "acme",
+				"baggage.user_id":   "42",
+			},
+		},
+		{
+			name:   "kv",
+			fields: BaggageFields{Members: []string{"tenant_id", "user_id"}},
+			log:    func(ctx context.Context) { InfoKV(ctx, "message", "order_id", 7) },
+			expected: map[string]interface{}{
+				"tenant_id": "acme",
+				"user_id":   "42",
+				"order_id":  float64(7),
+			},
+		},
+		{
+			name:   "overridden by AddKV",
+			fields: BaggageFields{Members: []string{"tenant_id", "user_id"}},
+			log:    func(ctx context.Context) { Info(AddKV(ctx, "user_id", "admin"), "message") },
+			expected: map[string]interface{}{
+				"tenant_id": "acme",
+				"user_id":   "admin",
+			},
+		},
+		{
+			name:   "overridden by the caller",
+			fields: BaggageFields{Members: []string{"tenant_id", "user_id"}},
+			log: func(ctx context.Context) {
+				InfoKV(AddKV(ctx, "user_id", "admin"), "message", "tenant_id", "other")
+			},
+			expected: map[string]interface{}{
+				"tenant_id": "other",
+				"user_id":   "admin",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf.Reset()
+			SetBaggageFields(tc.fields)
+			require.Equal(t, tc.fields, CurrentBaggageFields())
+
+			tc.log(ctx)
+
+			lines := decodeLines(t, &buf)
+			require.Len(t, lines, 1)
+			for _, key := range []string{"ts", "level", "message"} {
+				delete(lines[0], key)
+			}
+			require.Equal(t, tc.expected, lines[0])
+		})
+	}
+}
+
+func TestBaggageFields(t *testing.T) {
+	t.Parallel()
+
+	bag := testBaggage(t, "user_id=42,tenant_id=acme;tier=gold")
+
+	fields := AllBaggageFields("").Fields(bag)
+	keys := make([]string, 0, len(fields))
+	for _, f := range fields {
+		keys = append(keys, f.Key+"="+f.String)
+	}
+	require.Equal(t, []string{"tenant_id=acme", "user_id=42"}, keys)
+
+	require.Empty(t, BaggageFields{Members: []string{"user_id"}}.Fields(baggage.Baggage{}))
+}
+
+func TestBaggageLoggerCached(t *testing.T) {
+	SetBaggageFields(BaggageFields{Members: []string{"tenant_id"}})
+	t.Cleanup(func() { SetBaggageFields(BaggageFields{}) })
+
+	ctx := ToContext(context.Background(), NewWithSink(zapcore.DebugLevel, &bytes.Buffer{}))
+	ctx = AddKV(ctx, "request_id", "1")
+	acme := baggage.ContextWithBaggage(ctx, testBaggage(t, "tenant_id=acme"))
+
+	l := FromContext(acme)
+	require.Same(t, l, FromContext(acme))
+	// the same members in another baggage
+	require.Same(t, l, FromContext(baggage.ContextWithBaggage(ctx, testBaggage(t, "tenant_id=acme,user_id=42"))))
+
+	require.NotSame(t, l, FromContext(baggage.ContextWithBaggage(ctx, testBaggage(t, "tenant_id=other"))))
+	require.NotSame(t, l, FromContext(AddKV(acme, "request_id", "2")))
+
+	SetBaggageFields(AllBaggageFields(""))
+	require.NotSame(t, l, FromContext(acme))
+}
@@ -0,0 +1,206 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Encodings supported by Config
+const (
+	EncodingJSON    = "json"
+	EncodingConsole = "console"
+)
+
+// Config describes how NewFromConfig builds a logger.
+//
+// The struct can be decoded from JSON or YAML, start from DefaultConfig
+// and decode on top of it so omitted values keep their defaults.
+// An empty key disables the corresponding field in the output.
+type Config struct {
+	// Level global log_level set by NewFromConfig, the logger follows its changes
+	Level zapcore.Level `json:"level" yaml:"level"`
+	// Encoding either "json" or "console" (human readable, colored on terminals)
+	Encoding string `json:"encoding" yaml:"encoding"`
+
+	MessageKey    string `json:"message_key" yaml:"message_key"`
+	LevelKey      string `json:"level_key" yaml:"level_key"`
+	TimeKey       string `json:"time_key" yaml:"time_key"`
+	NameKey       string `json:"name_key" yaml:"name_key"`
+	CallerKey     string `json:"caller_key" yaml:"caller_key"`
+	FunctionKey   string `json:"function_key" yaml:"function_key"`
+	StacktraceKey string `json:"stacktrace_key" yaml:"stacktrace_key"`
+
+	// TimeFormat one of "iso8601", "rfc3339", "rfc3339nano", "epoch",
+	// "millis", "nanos" or a custom time layout (e.g. "2006-01-02 15:04:05")
+	TimeFormat string `json:"time_format" yaml:"time_format"`
+	// DurationEncoding one of "seconds", "nanos", "millis" or "string"
+	DurationEncoding string `json:"duration_encoding" yaml:"duration_encoding"`
+	// LevelEncoding either "lowercase" or "capital"
+	LevelEncoding string `json:"level_encoding" yaml:"level_encoding"`
+
+	// Caller annotates every entry with the caller file & line
+	Caller bool `json:"caller" yaml:"caller"`
+	// StacktraceLevel records a stacktrace for entries at or above it,
+	// nil disables stacktraces
+	StacktraceLevel *zapcore.Level `json:"stacktrace_level,omitempty" yaml:"stacktrace_level,omitempty"`
+
+	// OutputPaths sinks the entries are written to ("stdout", "stderr", file paths or registered zap sinks)
+	OutputPaths []string `json:"output_paths" yaml:"output_paths"`
+	// ErrorOutputPaths sinks for the logger internal errors
+	ErrorOutputPaths []string `json:"error_output_paths" yaml:"error_output_paths"`
+}
+
+// DefaultConfig Get the config matching the output of New
+func DefaultConfig() Config {
+	return Config{
+		Level:            zapcore.ErrorLevel,
+		Encoding:         EncodingJSON,
+		MessageKey:       "message",
+		LevelKey:         "level",
+		TimeKey:          "ts",
+		NameKey:          "logger",
+		CallerKey:        "caller",
+		FunctionKey:      "function",
+		StacktraceKey:    "stacktrace",
+		TimeFormat:       "iso8601",
+		DurationEncoding: "seconds",
+		LevelEncoding:    "lowercase",
+		OutputPaths:      []string{"stdout"},
+		ErrorOutputPaths: []string{"stderr"},
+	}
+}
+
+// NewFromConfig create a new logger described by the config, like zap.Open
+// the returned function closes the files opened for the output paths once the logger is no longer used.
+// The logger follows the global log_level, which is set to the config level
+// (like LOG_LEVEL), so SetLevel & LevelHandler change it.
+func NewFromConfig(cfg Config, options ...zap.Option) (*zap.SugaredLogger, func(), error) {
+	l, closeSinks, err := newFromConfig(cfg, defaultLevel, options...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defaultLevel.SetLevel(cfg.Level)
+	return l, closeSinks, nil
+}
+
+func newFromConfig(cfg Config, level zapcore.LevelEnabler, options ...zap.Option) (*zap.SugaredLogger, func(), error) {
+	encCfg, err := cfg.encoderConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outputPaths := cfg.OutputPaths
+	if len(outputPaths) == 0 {
+		outputPaths = []string{"stdout"}
+	}
+
+	var enc zapcore.Encoder
+	switch cfg.Encoding {
+	case "", EncodingJSON:
+		enc = zapcore.NewJSONEncoder(encCfg)
+	case EncodingConsole:
+		enc = NewConsoleEncoder(encCfg, pathsColorEnabled(outputPaths))
+	default:
+		return nil, nil, fmt.Errorf("logger: unknown encoding %q", cfg.Encoding)
+	}
+
+	sink, closeSink, err := zap.Open(outputPaths...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("logger: open output paths: %w", err)
+	}
+
+	closeSinks := closeSink
+	opts := make([]zap.Option, 0, len(options)+3)
+	if len(cfg.ErrorOutputPaths) > 0 {
+		errSink, closeErrSink, err := zap.Open(cfg.ErrorOutputPaths...)
+		if err != nil {
+			closeSink()
+			return nil, nil, fmt.Errorf("logger: open error output paths: %w", err)
+		}
+		opts = append(opts, zap.ErrorOutput(errSink))
+		closeSinks = func() {
+			closeSink()
+			closeErrSink()
+		}
+	}
+	if cfg.Caller {
+		opts = append(opts, zap.AddCaller())
+	}
+	if cfg.StacktraceLevel != nil {
+		opts = append(opts, zap.AddStacktrace(*cfg.StacktraceLevel))
+	}
+	opts = append(opts, options...)
+
+	return zap.New(newZapCore(level, enc, sink), opts...).Sugar(), closeSinks, nil
+}
+
+// encoderConfig Build zap encoder config out of the config
+func (cfg Config) encoderConfig() (zapcore.EncoderConfig, error) {
+	encCfg := zapcore.EncoderConfig{
+		MessageKey:    cfg.MessageKey,
+		LevelKey:      cfg.LevelKey,
+		TimeKey:       cfg.TimeKey,
+		NameKey:       cfg.NameKey,
+		CallerKey:     cfg.CallerKey,
+		FunctionKey:   cfg.FunctionKey,
+		StacktraceKey: cfg.StacktraceKey,
+		LineEnding:    zapcore.DefaultLineEnding,
+		EncodeTime:    timeEncoder(cfg.TimeFormat),
+		EncodeCaller:  zapcore.ShortCallerEncoder,
+	}
+
+	switch strings.ToLower(cfg.DurationEncoding) {
+	case "", "seconds":
+		encCfg.EncodeDuration = zapcore.SecondsDurationEncoder
+	case "nanos":
+		encCfg.EncodeDuration = zapcore.NanosDurationEncoder
+	case "millis", "ms":
+		encCfg.EncodeDuration = zapcore.MillisDurationEncoder
+	case "string":
+		encCfg.EncodeDuration = zapcore.StringDurationEncoder
+	default:
+		return encCfg, fmt.Errorf("logger: unknown duration encoding %q", cfg.DurationEncoding)
+	}
+
+	switch strings.ToLower(cfg.LevelEncoding) {
+	case "", "lowercase":
+		encCfg.EncodeLevel = zapcore.LowercaseLevelEncoder
+	case "capital":
+		encCfg.EncodeLevel = zapcore.CapitalLevelEncoder
+	default:
+		return encCfg, fmt.Errorf("logger: unknown level encoding %q", cfg.LevelEncoding)
+	}
+
+	return encCfg, nil
+}
+
+// timeEncoder Get the time encoder by its name,
+// unknown names are treated as a time layout
+func timeEncoder(format string) zapcore.TimeEncoder {
+	switch strings.ToLower(format) {
+	case "", "iso8601":
+		return zapcore.ISO8601TimeEncoder
+	case "rfc3339":
+		return zapcore.RFC3339TimeEncoder
+	case "rfc3339nano":
+		return zapcore.RFC3339NanoTimeEncoder
+	case "epoch":
+		return zapcore.EpochTimeEncoder
+	case "millis":
+		return zapcore.EpochMillisTimeEncoder
+	case "nanos":
+		return zapcore.EpochNanosTimeEncoder
+	default:
+		return zapcore.TimeEncoderOfLayout(format)
+	}
+}
+
+// defaultEncoderConfig Encoder config used by New & NewWithSink
+func defaultEncoderConfig() zapcore.EncoderConfig {
+	encCfg, _ := DefaultConfig().encoderConfig()
+	return encCfg
+}
@@ -0,0 +1,201 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
+)
+
+func TestConfigUnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	cfg := DefaultConfig()
+	err := json.Unmarshal([]byte(`{
+		"level": "debug",
+		"message_key": "msg",
+		"time_format": "rfc3339",
+		"stacktrace_level": "warn",
+		"output_paths": ["stderr"]
+	}`), &cfg)
+	require.NoError(t, err)
+
+	require.Equal(t, zapcore.DebugLevel, cfg.Level)
+	require.Equal(t, "msg", cfg.MessageKey)
+	require.Equal(t, "rfc3339", cfg.TimeFormat)
+	require.NotNil(t, cfg.StacktraceLevel)
+	require.Equal(t, zapcore.WarnLevel, *cfg.StacktraceLevel)
+	require.Equal(t, []string{"stderr"}, cfg.OutputPaths)
+
+	// omitted values keep their defaults
+	require.Equal(t, "ts", cfg.TimeKey)
+	require.Equal(t, EncodingJSON, cfg.Encoding)
+}
+
+func TestConfigUnmarshalYAML(t *testing.T) {
+	t.Parallel()
+
+	cfg := DefaultConfig()
+	err := yaml.Unmarshal([]byte(`
+level: debug
+encoding: console
+message_key: msg
+time_format: rfc3339
+duration_encoding: string
+caller: true
+stacktrace_level: warn
+output_paths: [stderr, /var/log/app.log]
+error_output_paths: [stdout]
+`), &cfg)
+	require.NoError(t, err)
+
+	require.Equal(t, zapcore.DebugLevel, cfg.Level)
+	require.Equal(t, EncodingConsole, cfg.Encoding)
+	require.Equal(t, "msg", cfg.MessageKey)
+	require.Equal(t, "rfc3339", cfg.TimeFormat)
+	require.Equal(t, "string", cfg.DurationEncoding)
+	require.True(t, cfg.Caller)
+	require.NotNil(t, cfg.StacktraceLevel)
+	require.Equal(t, zapcore.WarnLevel, *cfg.StacktraceLevel)
+	require.Equal(t, []string{"stderr", "/var/log/app.log"}, cfg.OutputPaths)
+	require.Equal(t, []string{"stdout"}, cfg.ErrorOutputPaths)
+
+	// omitted values keep their defaults
+	require.Equal(t, "ts", cfg.TimeKey)
+	require.Equal(t, "lowercase", cfg.LevelEncoding)
+}
+
+func TestNewFromConfigClosesSinks(t *testing.T) {
+	prevLevel := GlobalLevel().Level()
+	t.Cleanup(func() { SetLevel(prevLevel) })
+
+	dir := t.TempDir()
+	cfg := DefaultConfig()
+	cfg.OutputPaths = []string{filepath.Join(dir, "out.log")}
+	cfg.ErrorOutputPaths = []string{filepath.Join(dir, "errors.log")}
+
+	l, closeSinks, err := NewFromConfig(cfg)
+	require.NoError(t, err)
+
+	l.Error("before close")
+	closeSinks()
+
+	// the closed file can't be written anymore
+	l.Error("after close")
+
+	b, err := os.ReadFile(cfg.OutputPaths[0])
+	require.NoError(t, err)
+	require.Contains(t, string(b), "before close")
+	require.NotContains(t, string(b), "after close")
+}
+
+func TestNewFromConfig(t *testing.T) {
+	prevLevel := GlobalLevel().Level()
+	t.Cleanup(func() { SetLevel(prevLevel) })
+
+	path := filepath.Join(t.TempDir(), "out.log")
+
+	cfg := DefaultConfig()
+	cfg.Level = zapcore.InfoLevel
+	cfg.MessageKey = "msg"
+	cfg.TimeKey = "time"
+	cfg.TimeFormat = "2006"
+	cfg.DurationEncoding = "string"
+	cfg.OutputPaths = []string{path}
+
+	l, closeSinks, err := NewFromConfig(cfg)
+	require.NoError(t, err)
+	defer closeSinks()
+
+	l.Debug("skipped")
+	l.Info("hello world")
+	require.NoError(t, l.Sync())
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &decoded))
+
+	require.Equal(t, "hello world", decoded["msg"])
+	require.Equal(t, "info", decoded["level"])
+	require.Len(t, decoded["time"], 4)
+	require.NotContains(t, decoded, "message")
+	require.NotContains(t, decoded, "ts")
+}
+
+func TestNewFromConfigFollowsLevel(t *testing.T) {
+	prev, prevLevel := Logger(), GlobalLevel().Level()
+	t.Cleanup(func() {
+		SetLogger(prev)
+		SetLevel(prevLevel)
+	})
+
+	path := filepath.Join(t.TempDir(), "out.log")
+	cfg := DefaultConfig()
+	cfg.Level = zapcore.WarnLevel
+	cfg.OutputPaths = []string{path}
+
+	l, closeSinks, err := NewFromConfig(cfg)
+	require.NoError(t, err)
+	defer closeSinks()
+	SetLogger(l)
+	require.Equal(t, zapcore.WarnLevel, EffectiveLevel(""))
+
+	// the level changed at runtime applies to the logger
+	serveLevelHandler(t, NewLevelHandler(), http.MethodPut, "/", `{"level":"debug"}`, http.StatusOK)
+	require.Equal(t, zapcore.DebugLevel, EffectiveLevel(""))
+
+	Debug(context.Background(), "debug message")
+	require.NoError(t, l.Sync())
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(b), "debug message")
+}
+
+func TestNewFromConfigInvalid(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		modify func(cfg *Config)
+	}{
+		{
+			name:   "unknown encoding",
+			modify: func(cfg *Config) { cfg.Encoding = "xml" },
+		},
+		{
+			name:   "unknown duration encoding",
+			modify: func(cfg *Config) { cfg.DurationEncoding = "weeks" },
+		},
+		{
+			name:   "unknown level encoding",
+			modify: func(cfg *Config) { cfg.LevelEncoding = "rainbow" },
+		},
+		{
+			name:   "unknown output scheme",
+			modify: func(cfg *Config) { cfg.OutputPaths = []string{"unknown://sink"} },
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := DefaultConfig()
+			tc.modify(&cfg)
+
+			_, _, err := NewFromConfig(cfg)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,345 @@
+package logger
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// consoleMessageWidth Width the message is padded to before the fields
+const consoleMessageWidth = 40
+
+var consolePool = buffer.NewPool()
+
+// consolePrefixes Level prefixes & colors, the same as the cli package
+var consolePrefixes = map[zapcore.Level]struct {
+	text  string
+	color color.Attribute
+}{
+	zapcore.DebugLevel:  {"[DBG]", color.FgCyan},
+	zapcore.InfoLevel:   {"[INF]", color.FgGreen},
+	zapcore.WarnLevel:   {"[WAR]", color.FgYellow},
+	zapcore.ErrorLevel:  {"[ERR]", color.FgRed},
+	zapcore.DPanicLevel: {"[PAN]", color.FgMagenta},
+	zapcore.PanicLevel:  {"[PAN]", color.FgMagenta},
+	zapcore.FatalLevel:  {"[FAT]", color.FgMagenta},
+}
+
+// consoleEncoder Human readable encoder for local development
+//
+//	2024-05-01T10:00:00.000Z [INF] GetApples: apples fetched    count=5 trace_id=55e0…
+type consoleEncoder struct {
+	cfg     zapcore.EncoderConfig
+	colored bool
+
+	// fields Context fields already rendered as " key=value"
+	fields *buffer.Buffer
+	// namespace Prefix of the keys added after OpenNamespace
+	namespace string
+}
+
+// NewConsoleEncoder create an encoder rendering colored level prefixes,
+// an aligned message and the fields as dim key=value pairs
+func NewConsoleEncoder(cfg zapcore.EncoderConfig, colored bool) zapcore.Encoder {
+	return &consoleEncoder{
+		cfg:     cfg,
+		colored: colored,
+		fields:  consolePool.Get(),
+	}
+}
+
+// NewConsole create a new logger writing human readable lines to stdout,
+// colors are used only when stdout is a terminal and NO_COLOR is empty,
+// the level follows the same rules as New
+func NewConsole(level zapcore.LevelEnabler, options ...zap.Option) *zap.SugaredLogger {
+	level = globalBoundLevel(level)
+	if level == nil {
+		level = defaultLevel
+	}
+
+	enc := NewConsoleEncoder(defaultEncoderConfig(), colorEnabled(os.Stdout))
+	return zap.New(newZapCore(level, enc, zapcore.AddSync(os.Stdout)), options...).Sugar()
+}
+
+// colorDisabled Check whether the environment disables the colors,
+// a NO_COLOR set to an empty value doesn't (see https://no-color.org)
+func colorDisabled() bool {
+	return os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb"
+}
+
+// colorEnabled Check whether colors should be written to the writer
+func colorEnabled(w io.Writer) bool {
+	if colorDisabled() {
+		return false
+	}
+
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// pathsColorEnabled Check whether colors should be written to all the output paths
+func pathsColorEnabled(paths []string) bool {
+	if len(paths) == 0 {
+		return false
+	}
+
+	for _, p := range paths {
+		switch p {
+		case "stdout":
+			if !colorEnabled(os.Stdout) {
+				return false
+			}
+		case "stderr":
+			if !colorEnabled(os.Stderr) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (enc *consoleEncoder) paint(s string, attrs ...color.Attribute) string {
+	if !enc.colored {
+		return s
+	}
+
+	c := color.New(attrs...)
+	c.EnableColor()
+	return c.Sprint(s)
+}
+
+func (enc *consoleEncoder) Clone() zapcore.Encoder {
+	return enc.clone()
+}
+
+func (enc *consoleEncoder) clone() *consoleEncoder {
+	clone := &consoleEncoder{
+		cfg:       enc.cfg,
+		colored:   enc.colored,
+		fields:    consolePool.Get(),
+		namespace: enc.namespace,
+	}
+	clone.fields.AppendBytes(enc.fields.Bytes())
+	return clone
+}
+
+func (enc *consoleEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := enc.clone()
+	defer final.fields.Free()
+
+	for i := range fields {
+		fields[i].AddTo(final)
+	}
+
+	line := consolePool.Get()
+
+	if enc.cfg.TimeKey != "" && enc.cfg.EncodeTime != nil {
+		line.AppendString(enc.paint(encodePrimitive(func(ae zapcore.PrimitiveArrayEncoder) {
+			enc.cfg.EncodeTime(ent.Time, ae)
+		}), color.Faint))
+		line.AppendByte(' ')
+	}
+
+	if enc.cfg.LevelKey != "" {
+		prefix, ok := consolePrefixes[ent.Level]
+		if !ok {
+			prefix.text, prefix.color = "["+ent.Level.CapitalString()+"]", color.FgWhite
+		}
+		line.AppendString(enc.paint(prefix.text, prefix.color))
+		line.AppendByte(' ')
+	}
+
+	if enc.cfg.NameKey != "" && ent.LoggerName != "" {
+		line.AppendString(enc.paint(ent.LoggerName+":", color.Bold))
+		line.AppendByte(' ')
+	}
+
+	line.AppendString(ent.Message)
+
+	if final.fields.Len() > 0 {
+		if pad := consoleMessageWidth - len(ent.Message); pad > 0 {
+			line.AppendString(strings.Repeat(" ", pad))
+		}
+		line.AppendBytes(final.fields.Bytes())
+	}
+
+	if enc.cfg.CallerKey != "" && ent.Caller.Defined {
+		line.AppendByte(' ')
+		line.AppendString(enc.paint(ent.Caller.TrimmedPath(), color.Faint))
+	}
+
+	if enc.cfg.StacktraceKey != "" && ent.Stack != "" {
+		for _, frame := range strings.Split(ent.Stack, "\n") {
+			line.AppendString("\n    ")
+			line.AppendString(enc.paint(strings.ReplaceAll(frame, "\t", "    "), color.Faint))
+		}
+	}
+
+	if enc.cfg.LineEnding != "" {
+		line.AppendString(enc.cfg.LineEnding)
+	} else {
+		line.AppendString(zapcore.DefaultLineEnding)
+	}
+
+	return line, nil
+}
+
+// encodePrimitive Render a value written by one of the encoder config functions
+func encodePrimitive(encode func(zapcore.PrimitiveArrayEncoder)) string {
+	enc := zapcore.NewMapObjectEncoder()
+	_ = enc.AddArray("v", zapcore.ArrayMarshalerFunc(func(ae zapcore.ArrayEncoder) error {
+		encode(ae)
+		return nil
+	}))
+
+	if values, ok := enc.Fields["v"].([]interface{}); ok && len(values) > 0 {
+		return fmt.Sprint(values[0])
+	}
+	return ""
+}
+
+// addField Append a dim key=value pair, quoting values with spaces
+func (enc *consoleEncoder) addField(key, value string) {
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		value = strconv.Quote(value)
+	}
+
+	enc.fields.AppendByte(' ')
+	enc.fields.AppendString(enc.paint(enc.namespace+key+"="+value, color.Faint))
+}
+
+// addJSON Append a value rendered as JSON
+func (enc *consoleEncoder) addJSON(key string, value interface{}) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	enc.fields.AppendByte(' ')
+	enc.fields.AppendString(enc.paint(enc.namespace+key+"="+string(b), color.Faint))
+	return nil
+}
+
+func (enc *consoleEncoder) AddArray(key string, marshaler zapcore.ArrayMarshaler) error {
+	m := zapcore.NewMapObjectEncoder()
+	if err := m.AddArray(key, marshaler); err != nil {
+		return err
+	}
+	return enc.addJSON(key, m.Fields[key])
+}
+
+func (enc *consoleEncoder) AddObject(key string, marshaler zapcore.ObjectMarshaler) error {
+	m := zapcore.NewMapObjectEncoder()
+	if err := m.AddObject(key, marshaler); err != nil {
+		return err
+	}
+	return enc.addJSON(key, m.Fields[key])
+}
+
+func (enc *consoleEncoder) AddReflected(key string, value interface{}) error {
+	return enc.addJSON(key, value)
+}
+
+func (enc *consoleEncoder) OpenNamespace(key string) {
+	enc.namespace += key + "."
+}
+
+func (enc *consoleEncoder) AddBinary(key string, value []byte) {
+	enc.addField(key, base64.StdEncoding.EncodeToString(value))
+}
+
+func (enc *consoleEncoder) AddByteString(key string, value []byte) {
+	enc.addField(key, string(value))
+}
+
+func (enc *consoleEncoder) AddBool(key string, value bool) {
+	enc.addField(key, strconv.FormatBool(value))
+}
+
+func (enc *consoleEncoder) AddComplex128(key string, value complex128) {
+	enc.addField(key, strconv.FormatComplex(value, 'f', -1, 128))
+}
+
+func (enc *consoleEncoder) AddComplex64(key string, value complex64) {
+	enc.addField(key, strconv.FormatComplex(complex128(value), 'f', -1, 64))
+}
+
+func (enc *consoleEncoder) AddDuration(key string, value time.Duration) {
+	enc.addField(key, value.String())
+}
+
+func (enc *consoleEncoder) AddFloat64(key string, value float64) {
+	enc.addField(key, strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+func (enc *consoleEncoder) AddFloat32(key string, value float32) {
+	enc.addField(key, strconv.FormatFloat(float64(value), 'f', -1, 32))
+}
+
+func (enc *consoleEncoder) AddInt(key string, value int) {
+	enc.addField(key, strconv.FormatInt(int64(value), 10))
+}
+
+func (enc *consoleEncoder) AddInt64(key string, value int64) {
+	enc.addField(key, strconv.FormatInt(value, 10))
+}
+
+func (enc *consoleEncoder) AddInt32(key string, value int32) {
+	enc.addField(key, strconv.FormatInt(int64(value), 10))
+}
+
+func (enc *consoleEncoder) AddInt16(key string, value int16) {
+	enc.addField(key, strconv.FormatInt(int64(value), 10))
+}
+
+func (enc *consoleEncoder) AddInt8(key string, value int8) {
+	enc.addField(key, strconv.FormatInt(int64(value), 10))
+}
+
+func (enc *consoleEncoder) AddString(key, value string) {
+	enc.addField(key, value)
+}
+
+func (enc *consoleEncoder) AddTime(key string, value time.Time) {
+	enc.addField(key, value.Format(time.RFC3339Nano))
+}
+
+func (enc *consoleEncoder) AddUint(key string, value uint) {
+	enc.addField(key, strconv.FormatUint(uint64(value), 10))
+}
+
+func (enc *consoleEncoder) AddUint64(key string, value uint64) {
+	enc.addField(key, strconv.FormatUint(value, 10))
+}
+
+func (enc *consoleEncoder) AddUint32(key string, value uint32) {
+	enc.addField(key, strconv.FormatUint(uint64(value), 10))
+}
+
+func (enc *consoleEncoder) AddUint16(key string, value uint16) {
+	enc.addField(key, strconv.FormatUint(uint64(value), 10))
+}
+
+func (enc *consoleEncoder) AddUint8(key string, value uint8) {
+	enc.addField(key, strconv.FormatUint(uint64(value), 10))
+}
+
+func (enc *consoleEncoder) AddUintptr(key string, value uintptr) {
+	enc.addField(key, "0x"+strconv.FormatUint(uint64(value), 16))
+}
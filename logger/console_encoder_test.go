@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestConsoleEncoder(t *testing.T) {
+	t.Parallel()
+
+	enc := NewConsoleEncoder(defaultEncoderConfig(), false)
+
+	ent := zapcore.Entry{
+		Level:      zapcore.WarnLevel,
+		Time:       time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		LoggerName: "GetApples",
+		Message:    "apples fetched",
+		Caller:     zapcore.NewEntryCaller(0, "/src/apples/handler.go", 42, true),
+		Stack:      "main.run\n\t/src/main.go:10",
+	}
+
+	buf, err := enc.EncodeEntry(ent, []zapcore.Field{
+		zap.Int("count", 5),
+		zap.String("user", "john doe"),
+		zap.Duration("took", 1500*time.Millisecond),
+		zap.Strings("tags", []string{"a", "b"}),
+		zap.Namespace("db"),
+		zap.String("table", "apples"),
+	})
+	require.NoError(t, err)
+
+	want := "2024-05-01T10:00:00.000Z [WAR] GetApples: apples fetched" + strings.Repeat(" ", consoleMessageWidth-len(ent.Message)) +
+		` count=5 user="john doe" took=1.5s tags=["a","b"] db.table=apples apples/handler.go:42` +
+		"\n    main.run\n        /src/main.go:10\n"
+	require.Equal(t, want, buf.String())
+}
+
+func TestConsoleEncoderColored(t *testing.T) {
+	t.Parallel()
+
+	enc := NewConsoleEncoder(defaultEncoderConfig(), true)
+
+	buf, err := enc.EncodeEntry(zapcore.Entry{Level: zapcore.ErrorLevel, Message: "boom"}, []zapcore.Field{
+		zap.Int("count", 5),
+	})
+	require.NoError(t, err)
+
+	require.Contains(t, buf.String(), "\x1b[31m[ERR]\x1b[0m")
+	require.Contains(t, buf.String(), "\x1b[2mcount=5\x1b[22m")
+}
+
+func TestConsoleEncoderWithSpanContext(t *testing.T) {
+	t.Parallel()
+
+	buf := bytes.Buffer{}
+	l := zap.New(newZapCore(zapcore.DebugLevel, NewConsoleEncoder(defaultEncoderConfig(), false), zapcore.AddSync(&buf))).Sugar()
+
+	tID, err := trace.TraceIDFromHex("55e02c160e0dbd1b441bf1d5dc3ea3d5")
+	require.NoError(t, err)
+	sID, err := trace.SpanIDFromHex("a48b167265f65931")
+	require.NoError(t, err)
+
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: tID,
+		SpanID:  sID,
+	}))
+	ctx = ToContext(ctx, l)
+
+	Info(ctx, "hello world")
+
+	require.Contains(t, buf.String(), "[INF] hello world")
+	require.Contains(t, buf.String(), " trace_id=55e02c160e0dbd1b441bf1d5dc3ea3d5 span_id=a48b167265f65931\n")
+}
+
+func TestColorEnabled(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	require.False(t, colorEnabled(&bytes.Buffer{}))
+	require.False(t, pathsColorEnabled([]string{"stdout"}))
+	require.False(t, pathsColorEnabled([]string{"/var/log/app.log"}))
+}
+
+func TestColorDisabled(t *testing.T) {
+	testCases := []struct {
+		name     string
+		noColor  string
+		term     string
+		expected bool
+	}{
+		{name: "NO_COLOR set", noColor: "1", term: "xterm", expected: true},
+		{name: "NO_COLOR empty", noColor: "", term: "xterm", expected: false},
+		{name: "dumb terminal", noColor: "", term: "dumb", expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tc.noColor)
+			t.Setenv("TERM", tc.term)
+
+			require.Equal(t, tc.expected, colorDisabled())
+		})
+	}
+}
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"context"
+	"sync/atomic"
 
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
@@ -12,6 +13,7 @@ type contextKey int
 
 const (
 	loggerContextKey contextKey = iota
+	callerSkipContextKey
 )
 
 // ToContext Attaches a logger to context
@@ -19,28 +21,50 @@ func ToContext(ctx context.Context, l *zap.SugaredLogger) context.Context {
 	return context.WithValue(ctx, loggerContextKey, l)
 }
 
-// FromContext Gets the logger from contet
+// FromContext Gets the logger from contet,
+// the fields added by AddKV & the baggage members are attached to it
 func FromContext(ctx context.Context) *zap.SugaredLogger {
+	return loggerWithKvs(ctx, loggerFromContext(ctx))
+}
+
+// loggerFromContext Gets the logger from context without the AddKV fields,
+// used when the fields are merged with the ones passed by the caller
+func loggerFromContext(ctx context.Context) *zap.SugaredLogger {
 	l := getLogger(ctx)
 
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
-		// if span is valid - inject trace_id & span_id to logger
-		l = loggerWithSpanContext(l, span.SpanContext())
+		// if span is valid - inject trace_id & span_id to logger,
+		// the enriched logger is computed once per span
+		l = loggerWithCachedSpan(l, span)
 	}
 
 	return l
 }
 
-// LevelFromContext Gets the log_level from the context logger
+// LevelFromContext Gets the effective log_level of the context logger,
+// including the override matching its name (see SetNameLevel)
 func LevelFromContext(ctx context.Context) zapcore.Level {
-	return FromContext(ctx).Level()
+	l := loggerFromContext(ctx).Desugar()
+	return effectiveLevel(l.Core(), l.Name())
+}
+
+// loggerWithKvs Attach the fields added by AddKV & the baggage members
+// (see SetBaggageFields) to the logger
+func loggerWithKvs(ctx context.Context, l *zap.SugaredLogger) *zap.SugaredLogger {
+	if withBaggage, ok := loggerWithBaggage(ctx, l); ok {
+		return withBaggage
+	}
+	if node := getKvNode(ctx); node != nil {
+		return node.loggerWith(l)
+	}
+	return l
 }
 
 // getLogger Get from context object
 // if none such logger exists then use the global logger instead
 func getLogger(ctx context.Context) *zap.SugaredLogger {
-	l := global
+	l := global.Load()
 
 	if logger, ok := ctx.Value(loggerContextKey).(*zap.SugaredLogger); ok {
 		l = logger
@@ -49,29 +73,50 @@ func getLogger(ctx context.Context) *zap.SugaredLogger {
 	return l
 }
 
-// loggerWithSpanContext Inject trace_id & span_id values to logger
-func loggerWithSpanContext(l *zap.SugaredLogger, spanCtx trace.SpanContext) *zap.SugaredLogger {
-	return l.Desugar().With(
-		zap.Stringer("trace_id", spanCtx.TraceID()),
-		zap.Stringer("span_id", spanCtx.SpanID()),
-	).Sugar()
+// loggerWithSpan Inject the trace fields (trace_id & span_id by default, see SetTraceFormatter)
+// to logger, the span itself is only carried to the loggers with an OpenTelemetry core
+// (see WithOTelLogs & WithSpanEvents)
+func loggerWithSpan(l *zap.SugaredLogger, span trace.Span, formatter TraceFormatter) *zap.SugaredLogger {
+	zl := l.Desugar()
+	fields := formatter.Fields(span.SpanContext())
+	if usesSpan(zl.Core()) {
+		fields = append(fields, spanField(span))
+	}
+	return zl.With(fields...).Sugar()
+}
+
+// WithCallerSkip Skip additional caller frames when the package functions
+// report the caller, for wrappers calling them on behalf of their callers
+//
+//	func logRequest(ctx context.Context, r *http.Request) {
+//		logger.Info(logger.WithCallerSkip(ctx, 1), r.URL.Path) // reports the caller of logRequest
+//	}
+func WithCallerSkip(ctx context.Context, skip int) context.Context {
+	return context.WithValue(ctx, callerSkipContextKey, callerSkipFromContext(ctx)+skip)
+}
+
+// callerSkipFromContext Get the caller frames added by WithCallerSkip
+func callerSkipFromContext(ctx context.Context) int {
+	skip, _ := ctx.Value(callerSkipContextKey).(int)
+	return skip
 }
 
-// WithName Set a name for the logger
+// WithName Set a name for the logger,
+// the trace fields are not stored so the logger stays valid for child spans
 func WithName(ctx context.Context, name string) context.Context {
-	l := FromContext(ctx).Named(name)
+	l := getLogger(ctx).Named(name)
 	return ToContext(ctx, l)
 }
 
 // WithKV Adds KV pair to logger from context
 func WithKV(ctx context.Context, key string, value any) context.Context {
-	l := FromContext(ctx).With(key, value)
+	l := getLogger(ctx).With(key, value)
 	return ToContext(ctx, l)
 }
 
 // WithFields Adds fields to logger from context
 func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
-	l := FromContext(ctx).Desugar().With(fields...).Sugar()
+	l := getLogger(ctx).Desugar().With(fields...).Sugar()
 	return ToContext(ctx, l)
 }
 
@@ -110,29 +155,127 @@ func mergeFields(filedFromContext, fieldsFromCaller []any) []any {
 
 type logFieldKeyType string
 
-var logFieldKey = logFieldKeyType("logger-fields")
+const logFieldKey = logFieldKeyType("logger-fields")
+
+// kvNode Fields added by a single AddKV call linked to the ones added before,
+// the nodes are immutable so contexts share their parents' fields without copying
+type kvNode struct {
+	parent *kvNode
+	fields []any
+	// size Number of fields in the node and its parents
+	size int
+
+	// resolved Fields of the chain with unique keys, computed once
+	resolved atomic.Pointer[[]any]
+	// logger Last logger the fields were attached to
+	logger atomic.Pointer[kvLogger]
+}
+
+type kvLogger struct {
+	base, logger *zap.SugaredLogger
+}
 
-// AddKV Adds KV pairs to
+// AddKV Adds KV pairs to the context, they are attached to every entry
+// logged with it, if a key already exists its value is replaced
 func AddKV(ctx context.Context, kvs ...any) context.Context {
 	if len(kvs) == 0 {
 		return ctx
 	}
 
-	kvsFromContext := getKvsFromContext(ctx)
-	additionalFields := globalMerger.sweetenFields(kvs)
+	fields := globalMerger.sweetenFields(kvs)
+	if len(fields) == 0 {
+		return ctx
+	}
+
+	parent := getKvNode(ctx)
+	node := &kvNode{
+		parent: parent,
+		fields: fields,
+		size:   len(fields),
+	}
+	if parent != nil {
+		node.size += parent.size
+	}
 
-	return context.WithValue(ctx, logFieldKey, mergeFields(kvsFromContext, additionalFields))
+	return context.WithValue(ctx, logFieldKey, node)
 }
 
-// getKvsFromContext Gets the KV values stored by AddKV function
+// getKvNode Gets the last node stored by AddKV function
+func getKvNode(ctx context.Context) *kvNode {
+	node, _ := ctx.Value(logFieldKey).(*kvNode)
+	return node
+}
+
+// getKvsFromContext Gets the KV values stored by AddKV function,
+// the returned slice is shared and must not be modified
 func getKvsFromContext(ctx context.Context) []any {
-	if kvs, ok := ctx.Value(logFieldKey).([]any); ok {
+	return getKvNode(ctx).resolve()
+}
+
+// resolve Get the fields of the chain in the order their keys were first
+// added, with the value added last for every key
+func (n *kvNode) resolve() []any {
+	if n == nil {
+		return nil
+	}
+	if resolved := n.resolved.Load(); resolved != nil {
+		return *resolved
+	}
 
-		// create a copy from the fields since the
-		// array can be modified by AddKV
-		kvsCopy := make([]any, len(kvs))
-		copy(kvsCopy, kvs)
-		return kvsCopy
+	chain := make([]*kvNode, 0, 8)
+	for node := n; node != nil; node = node.parent {
+		chain = append(chain, node)
 	}
-	return nil
+
+	var (
+		resolved = make([]any, 0, n.size)
+		index    map[string]int
+	)
+	if n.size > 8 {
+		index = make(map[string]int, n.size)
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		for _, f := range chain[i].fields {
+			key := f.(zap.Field).Key
+
+			j := -1
+			if index != nil {
+				if k, ok := index[key]; ok {
+					j = k
+				}
+			} else {
+				for k := range resolved {
+					if resolved[k].(zap.Field).Key == key {
+						j = k
+						break
+					}
+				}
+			}
+
+			if j >= 0 {
+				resolved[j] = f
+				continue
+			}
+			if index != nil {
+				index[key] = len(resolved)
+			}
+			resolved = append(resolved, f)
+		}
+	}
+
+	n.resolved.Store(&resolved)
+	return resolved
+}
+
+// loggerWith Get the logger with the fields attached, reusing
+// the last result while the fields are attached to the same logger
+func (n *kvNode) loggerWith(l *zap.SugaredLogger) *zap.SugaredLogger {
+	if cached := n.logger.Load(); cached != nil && cached.base == l {
+		return cached.logger
+	}
+
+	withKvs := l.With(n.resolve()...)
+	n.logger.Store(&kvLogger{base: l, logger: withKvs})
+	return withKvs
 }
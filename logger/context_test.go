@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"testing"
 
@@ -17,10 +18,13 @@ import (
 func TestFromContextGlobalLogger(t *testing.T) {
 	logger := FromContext(context.Background())
 
-	require.Equal(t, global, logger)
+	require.Equal(t, Logger(), logger)
 }
 
 func TestFromContextWithLogger(t *testing.T) {
+	prevLevel := GlobalLevel().Level()
+	t.Cleanup(func() { SetLevel(prevLevel) })
+
 	l := New(zapcore.DebugLevel)
 	ctx := ToContext(context.Background(), l)
 
@@ -30,10 +34,13 @@ func TestFromContextWithLogger(t *testing.T) {
 func TestLevelFromContextGlobalLogger(t *testing.T) {
 	lvl := LevelFromContext(context.Background())
 
-	require.Equal(t, global.Level(), lvl)
+	require.Equal(t, Logger().Level(), lvl)
 }
 
 func TestLevelFromContextWithLogger(t *testing.T) {
+	prevLevel := GlobalLevel().Level()
+	t.Cleanup(func() { SetLevel(prevLevel) })
+
 	l := New(zapcore.DPanicLevel)
 	ctx := ToContext(context.Background(), l)
 
@@ -54,7 +61,7 @@ func TestLoggerWithSpanContext(t *testing.T) {
 		SpanID:  sID,
 	})
 
-	l := loggerWithSpanContext(loggerWithWriter(&buf), sc)
+	l := loggerWithSpan(loggerWithWriter(&buf), trace.SpanFromContext(trace.ContextWithSpanContext(context.Background(), sc)), DefaultTraceFields())
 	l.Debug("hello world")
 
 	var decoded map[string]interface{}
@@ -67,6 +74,89 @@ func TestLoggerWithSpanContext(t *testing.T) {
 	require.Equal(t, "hello world", decoded["message"])
 }
 
+func TestFromContextSpanLoggerCached(t *testing.T) {
+	ctx := ToContext(context.Background(), loggerWithWriter(io.Discard))
+	ctx = trace.ContextWithSpanContext(ctx, testSpanContext(t, "a48b167265f65931"))
+
+	l := FromContext(ctx)
+	require.Same(t, l, FromContext(ctx))
+	require.NotSame(t, getLogger(ctx), l)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		FromContext(ctx)
+	})
+	require.Zero(t, allocs)
+}
+
+func TestWithNameDoesNotStoreSpanContext(t *testing.T) {
+	t.Parallel()
+
+	buf := bytes.Buffer{}
+	ctx := ToContext(context.Background(), loggerWithWriter(&buf))
+	ctx = trace.ContextWithSpanContext(ctx, testSpanContext(t, "a48b167265f65931"))
+	ctx = WithName(ctx, "GetApples")
+	ctx = WithKV(ctx, "apples", 500)
+
+	// child span
+	ctx = trace.ContextWithSpanContext(ctx, testSpanContext(t, "0102030405060708"))
+	FromContext(ctx).Debug("hello world")
+
+	require.Equal(t, 1, bytes.Count(buf.Bytes(), []byte(`"span_id"`)), buf.String())
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Equal(t, "0102030405060708", decoded["span_id"])
+	require.Equal(t, "GetApples", decoded["logger"])
+	require.EqualValues(t, 500, decoded["apples"])
+}
+
+func BenchmarkFromContext(b *testing.B) {
+	base := ToContext(context.Background(), loggerWithWriter(io.Discard))
+
+	tID, _ := trace.TraceIDFromHex("55e02c160e0dbd1b441bf1d5dc3ea3d5")
+	sID, _ := trace.SpanIDFromHex("a48b167265f65931")
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{TraceID: tID, SpanID: sID})
+	withSpan := trace.ContextWithSpanContext(base, spanCtx)
+	span := trace.SpanFromContext(withSpan)
+
+	b.Run("no span", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			FromContext(base)
+		}
+	})
+
+	b.Run("span, uncached", func(b *testing.B) {
+		l := getLogger(withSpan)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			loggerWithSpan(l, span, DefaultTraceFields())
+		}
+	})
+
+	b.Run("span", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			FromContext(withSpan)
+		}
+	})
+
+	b.Run("span, named", func(b *testing.B) {
+		ctx := WithName(WithName(withSpan, "GetApples"), "DB")
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			FromContext(ctx)
+		}
+	})
+
+	b.Run("span, Info", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			Info(withSpan, "hello world")
+		}
+	})
+}
+
 func TestLoggerWithName(t *testing.T) {
 	t.Parallel()
 
@@ -131,6 +221,20 @@ func TestLoggerWithFields(t *testing.T) {
 	require.EqualValues(t, 420, decoded["kafka-partition"])
 }
 
+func testSpanContext(t *testing.T, spanID string) trace.SpanContext {
+	t.Helper()
+
+	tID, err := trace.TraceIDFromHex("55e02c160e0dbd1b441bf1d5dc3ea3d5")
+	require.NoError(t, err)
+	sID, err := trace.SpanIDFromHex(spanID)
+	require.NoError(t, err)
+
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: tID,
+		SpanID:  sID,
+	})
+}
+
 func loggerWithWriter(w io.Writer) *zap.SugaredLogger {
 	sink := zapcore.AddSync(w)
 	return zap.New(
@@ -271,6 +375,48 @@ func TestAddKVAndFieldsFromContext(t *testing.T) {
 	}
 }
 
+func TestAddKVAppliedToEveryFunction(t *testing.T) {
+	t.Parallel()
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	ctx := ToContext(context.Background(), zap.New(core).Sugar())
+	ctx = AddKV(ctx, "request_id", "abc", "user", 1)
+
+	Debug(ctx, "debug")
+	Infof(ctx, "info %d", 1)
+	Warn(ctx, "warn")
+	Error(ctx, "error")
+	FromContext(ctx).Info("from context")
+	WithName(ctx, "named").Value(loggerContextKey).(*zap.SugaredLogger).Info("named")
+	FromContext(WithName(ctx, "named")).Info("named from context")
+
+	want := []zap.Field{
+		zap.Any("request_id", "abc"),
+		zap.Any("user", 1),
+	}
+
+	records := logs.TakeAll()
+	require.Len(t, records, 7)
+	for _, record := range records {
+		if record.Message == "named" {
+			// the fields are not baked into the stored logger
+			require.Empty(t, record.Context)
+			continue
+		}
+		require.Equal(t, want, record.Context, record.Message)
+	}
+
+	// the caller fields override the context ones
+	InfoKV(ctx, "kv", "user", 2)
+
+	records = logs.TakeAll()
+	require.Len(t, records, 1)
+	require.Equal(t, []zap.Field{
+		zap.Any("request_id", "abc"),
+		zap.Any("user", 2),
+	}, records[0].Context)
+}
+
 func TestMergeKVs(t *testing.T) {
 	t.Parallel()
 
@@ -391,6 +537,15 @@ func BenchmarkAddKV(b *testing.B) {
 			AddKV(ctx, "key3", "value3", "key4", "value4")
 		}
 	})
+
+	b.Run("deep context", func(b *testing.B) {
+		ctx := testDeepKVContext(ctx, 25)
+		b.ResetTimer()
+
+		for i := 0; i < b.N; i++ {
+			AddKV(ctx, "key3", "value3", "key4", "value4")
+		}
+	})
 }
 
 func BenchmarkMergeKVs(b *testing.B) {
@@ -423,6 +578,43 @@ func BenchmarkMergeKVs(b *testing.B) {
 			mergeKvs(ctx, "key2", "value2")
 		}
 	})
+
+	b.Run("deep user fields, empty final call fields", func(b *testing.B) {
+		ctx := testDeepKVContext(ctx, 25)
+
+		b.ResetTimer()
+
+		for i := 0; i < b.N; i++ {
+			mergeKvs(ctx)
+		}
+	})
+}
+
+func TestAddKVDeepContext(t *testing.T) {
+	t.Parallel()
+
+	ctx := testDeepKVContext(context.Background(), 20)
+	ctx = AddKV(ctx, "key3", "override", "key21", "new")
+	child := AddKV(ctx, "key0", "child")
+
+	got := getKvsFromContext(ctx)
+	require.Len(t, got, 21)
+	require.Equal(t, zap.Any("key0", 0), got[0])
+	require.Equal(t, zap.Any("key3", "override"), got[3])
+	require.Equal(t, zap.Any("key21", "new"), got[20])
+
+	// children never modify the fields of their parents
+	require.Equal(t, zap.Any("key0", "child"), getKvsFromContext(child)[0])
+	require.Equal(t, zap.Any("key0", 0), getKvsFromContext(ctx)[0])
+}
+
+// testDeepKVContext Context with the fields added by n AddKV calls
+// the way handlers add them layer by layer
+func testDeepKVContext(ctx context.Context, n int) context.Context {
+	for i := 0; i < n; i++ {
+		ctx = AddKV(ctx, fmt.Sprintf("key%d", i), i)
+	}
+	return ctx
 }
 
 func testNewLogEntry(message string, kvs ...zap.Field) observer.LoggedEntry {
@@ -0,0 +1,185 @@
+package logger
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// dedupeCore Core guaranteeing every key appears once in an entry,
+// a field overrides the previous one with the same key in place.
+//
+// The context fields are deduplicated and encoded once by With, the core
+// keeps them so fields added later (WithKV, AddKV, trace injection) can
+// still replace them. An entry field overriding a context key drops the
+// context field, only then are the context fields encoded again for the entry.
+// The fields without a key (zap.Inline, zap.Skip) are never deduplicated.
+type dedupeCore struct {
+	// base Core without the context fields
+	base zapcore.Core
+	// core Core with the context fields encoded
+	core   zapcore.Core
+	fields []zapcore.Field
+	// keys Index of the context keys, built for large contexts
+	keys map[string]struct{}
+}
+
+func newDedupeCore(core zapcore.Core) zapcore.Core {
+	return &dedupeCore{base: core, core: core}
+}
+
+func (c *dedupeCore) Level() zapcore.Level {
+	return zapcore.LevelOf(c.core)
+}
+
+func (c *dedupeCore) levelFor(name string) zapcore.Level {
+	return effectiveLevel(c.core, name)
+}
+
+func (c *dedupeCore) Enabled(l zapcore.Level) bool {
+	return c.core.Enabled(l)
+}
+
+func (c *dedupeCore) With(fields []zapcore.Field) zapcore.Core {
+	if len(fields) == 0 {
+		return c
+	}
+
+	merged := dedupeFields(c.fields, fields)
+	clone := &dedupeCore{
+		base:   c.base,
+		core:   c.base.With(merged),
+		fields: merged,
+	}
+	if len(merged) > 8 {
+		clone.keys = make(map[string]struct{}, len(merged))
+		for i := range merged {
+			if merged[i].Type == zapcore.NamespaceType {
+				break
+			}
+			if dedupable(merged[i]) {
+				clone.keys[merged[i].Key] = struct{}{}
+			}
+		}
+	}
+	return clone
+}
+
+func (c *dedupeCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *dedupeCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if c.overrides(fields) {
+		return c.base.Write(ent, dedupeFields(c.fields, fields))
+	}
+	if hasDuplicateKeys(fields) {
+		fields = dedupeFields(nil, fields)
+	}
+	return c.core.Write(ent, fields)
+}
+
+func (c *dedupeCore) Sync() error {
+	return c.core.Sync()
+}
+
+// overrides Whether any of the fields replaces a context field
+func (c *dedupeCore) overrides(fields []zapcore.Field) bool {
+	if len(c.fields) == 0 {
+		return false
+	}
+
+	for _, f := range fields {
+		if f.Type == zapcore.NamespaceType {
+			return false
+		}
+		if !dedupable(f) {
+			continue
+		}
+
+		if c.keys != nil {
+			if _, ok := c.keys[f.Key]; ok {
+				return true
+			}
+			continue
+		}
+		for i := range c.fields {
+			if c.fields[i].Type == zapcore.NamespaceType {
+				break
+			}
+			if c.fields[i].Key == f.Key && dedupable(c.fields[i]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasDuplicateKeys Whether a key appears twice outside of any namespace
+func hasDuplicateKeys(fields []zapcore.Field) bool {
+	for i := range fields {
+		if fields[i].Type == zapcore.NamespaceType {
+			return false
+		}
+		if !dedupable(fields[i]) {
+			continue
+		}
+		for j := 0; j < i; j++ {
+			if fields[j].Key == fields[i].Key && dedupable(fields[j]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// dedupable Whether the field can replace or be replaced by another one,
+// the fields without a key of their own are always kept
+func dedupable(f zapcore.Field) bool {
+	return f.Key != "" && f.Type != zapcore.InlineMarshalerType && f.Type != zapcore.SkipType
+}
+
+// dedupeFields Append the fields to a copy of dst replacing the ones with
+// the same key (see dedupable), once a namespace is opened the fields belong to it and
+// are appended as is
+func dedupeFields(dst, fields []zapcore.Field) []zapcore.Field {
+	if len(fields) == 0 && len(dst) == 0 {
+		return nil
+	}
+
+	merged := make([]zapcore.Field, len(dst), len(dst)+len(fields))
+	copy(merged, dst)
+
+	// top Number of the fields outside of any namespace
+	top := len(merged)
+	for i := range merged {
+		if merged[i].Type == zapcore.NamespaceType {
+			top = i
+			break
+		}
+	}
+
+	for _, f := range fields {
+		if top < len(merged) || f.Type == zapcore.NamespaceType {
+			merged = append(merged, f)
+			continue
+		}
+
+		replaced := false
+		for j := 0; j < top && dedupable(f); j++ {
+			if merged[j].Key == f.Key && dedupable(merged[j]) {
+				merged[j] = f
+				replaced = true
+				break
+			}
+		}
+
+		if !replaced {
+			merged = append(merged, f)
+			top++
+		}
+	}
+
+	return merged
+}
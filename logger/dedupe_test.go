@@ -0,0 +1,185 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestDedupeFields(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		dst    []zap.Field
+		fields []zap.Field
+		want   []zap.Field
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:   "unique keys",
+			dst:    []zap.Field{zap.Int("a", 1)},
+			fields: []zap.Field{zap.Int("b", 2)},
+			want:   []zap.Field{zap.Int("a", 1), zap.Int("b", 2)},
+		},
+		{
+			name:   "last writer wins in place",
+			dst:    []zap.Field{zap.Int("a", 1), zap.Int("b", 2)},
+			fields: []zap.Field{zap.String("a", "x"), zap.Int("c", 3), zap.String("a", "y")},
+			want:   []zap.Field{zap.String("a", "y"), zap.Int("b", 2), zap.Int("c", 3)},
+		},
+		{
+			name:   "fields of a namespace are kept",
+			dst:    []zap.Field{zap.Int("a", 1), zap.Namespace("ns"), zap.Int("a", 2)},
+			fields: []zap.Field{zap.Int("a", 3)},
+			want:   []zap.Field{zap.Int("a", 1), zap.Namespace("ns"), zap.Int("a", 2), zap.Int("a", 3)},
+		},
+		{
+			name:   "fields without a key are kept",
+			dst:    []zap.Field{zap.Inline(testInline{"a"}), zap.Skip()},
+			fields: []zap.Field{zap.Inline(testInline{"b"}), zap.Skip(), zap.Any("", 1)},
+			want: []zap.Field{
+				zap.Inline(testInline{"a"}), zap.Skip(),
+				zap.Inline(testInline{"b"}), zap.Skip(), zap.Any("", 1),
+			},
+		},
+		{
+			name:   "namespace with an existing key",
+			dst:    []zap.Field{zap.Int("a", 1)},
+			fields: []zap.Field{zap.Namespace("a"), zap.Int("a", 2)},
+			want:   []zap.Field{zap.Int("a", 1), zap.Namespace("a"), zap.Int("a", 2)},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dst := append([]zap.Field(nil), tc.dst...)
+			require.Equal(t, tc.want, dedupeFields(dst, tc.fields))
+			// the original fields are never modified
+			require.Equal(t, tc.dst, dst)
+		})
+	}
+}
+
+func TestDedupeCore(t *testing.T) {
+	t.Parallel()
+
+	buf := bytes.Buffer{}
+	ctx := ToContext(context.Background(), NewWithSink(zapcore.DebugLevel, &buf))
+
+	tID, err := trace.TraceIDFromHex("55e02c160e0dbd1b441bf1d5dc3ea3d5")
+	require.NoError(t, err)
+	sID, err := trace.SpanIDFromHex("a48b167265f65931")
+	require.NoError(t, err)
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: tID,
+		SpanID:  sID,
+	}))
+
+	ctx = WithName(ctx, "GetApples")
+	ctx = WithName(ctx, "AppleManager")
+	ctx = WithKV(ctx, "user", 1)
+	ctx = WithKV(ctx, "user", 2)
+	ctx = WithFields(ctx, zap.Int("user", 3))
+	ctx = AddKV(ctx, "user", 4)
+
+	Info(ctx, "hello world")
+	InfoKV(ctx, "hello world", "user", 5)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	for i, want := range []float64{4, 5} {
+		for _, key := range []string{`"user"`, `"trace_id"`, `"span_id"`} {
+			require.Equal(t, 1, strings.Count(lines[i], key), lines[i])
+		}
+
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(lines[i]), &decoded))
+		require.Equal(t, want, decoded["user"])
+		require.Equal(t, "GetApples.AppleManager", decoded["logger"])
+	}
+}
+
+type testInline struct{ key string }
+
+func (o testInline) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString(o.key, o.key)
+	return nil
+}
+
+type testCounter struct{ n int }
+
+func (c *testCounter) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddInt("n", c.n)
+	return nil
+}
+
+func TestDedupeCoreKeepsInlineFields(t *testing.T) {
+	t.Parallel()
+
+	buf := bytes.Buffer{}
+	l := NewWithSink(zapcore.DebugLevel, &buf).Desugar()
+	l.With(zap.Inline(testInline{"a"})).Info("message", zap.Inline(testInline{"b"}), zap.Inline(testInline{"c"}))
+
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 1)
+	require.Equal(t, "a", lines[0]["a"])
+	require.Equal(t, "b", lines[0]["b"])
+	require.Equal(t, "c", lines[0]["c"])
+}
+
+func TestDedupeCoreEncodesContextOnce(t *testing.T) {
+	t.Parallel()
+
+	buf := bytes.Buffer{}
+	counter := &testCounter{n: 1}
+	l := NewWithSink(zapcore.DebugLevel, &buf).Desugar().With(zap.Object("u", counter))
+	child := l.With(zap.Int("a", 1))
+
+	counter.n = 2
+	l.Info("message")
+	child.Info("message", zap.Int("b", 2))
+
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		require.Equal(t, map[string]interface{}{"n": float64(1)}, line["u"])
+	}
+	require.Equal(t, float64(1), lines[1]["a"])
+}
+
+func TestDedupeCoreLargeContext(t *testing.T) {
+	t.Parallel()
+
+	buf := bytes.Buffer{}
+	fields := make([]zap.Field, 0, 10)
+	for i := 0; i < 10; i++ {
+		fields = append(fields, zap.Int(strings.Repeat("k", i+1), i))
+	}
+	l := NewWithSink(zapcore.DebugLevel, &buf).Desugar().With(fields...)
+
+	l.Info("message", zap.String("kkk", "override"), zap.Int("new", 1), zap.Int("new", 2))
+
+	line := strings.TrimSpace(buf.String())
+	require.Equal(t, 1, strings.Count(line, `"kkk"`), line)
+	require.Equal(t, 1, strings.Count(line, `"new"`), line)
+
+	lines := decodeLines(t, &buf)
+	require.Equal(t, "override", lines[0]["kkk"])
+	require.Equal(t, float64(2), lines[0]["new"])
+	require.Equal(t, float64(9), lines[0]["kkkkkkkkkk"])
+}
@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"go.uber.org/multierr"
+)
+
+// Environment variables read by the package at startup
+const (
+	// EnvLevel global log_level (debug, info, warn, error, dpanic, panic, fatal)
+	EnvLevel = "LOG_LEVEL"
+	// EnvFormat output encoding (json, console)
+	EnvFormat = "LOG_FORMAT"
+	// EnvOutput comma separated output paths (stdout, stderr, file paths)
+	EnvOutput = "LOG_OUTPUT"
+	// EnvLevels comma separated per logger name overrides (e.g. "kafka=warn,GetApples.DB=debug")
+	EnvLevels = "LOG_LEVELS"
+	// EnvTraceFormat comma separated trace field formats (e.g. "default,datadog", see ParseTraceFormat)
+	EnvTraceFormat = "LOG_TRACE_FORMAT"
+)
+
+// closeEnvSinks Close the output files of the logger configured by setupFromEnv
+var closeEnvSinks func()
+
+// init Set the default logger value
+func init() {
+	if err := setupFromEnv(); err != nil {
+		fmt.Fprintf(os.Stderr, "logger: invalid environment, falling back to defaults: %v\n", err)
+	}
+}
+
+// setupFromEnv Configure the global logger & log_level out of the environment,
+// valid values are applied even if some of them are invalid
+func setupFromEnv() error {
+	cfg, err := ConfigFromEnv()
+
+	levels, levelsErr := parseNameLevels(os.Getenv(EnvLevels))
+	if levelsErr != nil {
+		err = multierr.Append(err, fmt.Errorf("%s: %w", EnvLevels, levelsErr))
+	}
+	nameLevels.set(levels)
+
+	if v, ok := lookupEnv(EnvTraceFormat); ok {
+		formatter, formatErr := ParseTraceFormat(v)
+		if formatErr != nil {
+			err = multierr.Append(err, fmt.Errorf("%s: %w", EnvTraceFormat, formatErr))
+		} else {
+			SetTraceFormatter(formatter)
+		}
+	}
+
+	defaultLevel.SetLevel(cfg.Level)
+
+	l, closeSinks, cfgErr := newFromConfig(cfg, defaultLevel)
+	if cfgErr != nil {
+		err = multierr.Append(err, cfgErr)
+		l, closeSinks, _ = newFromConfig(DefaultConfig(), defaultLevel)
+	}
+	SetLogger(l)
+
+	// the files of the logger configured before are released
+	if closeEnvSinks != nil {
+		closeEnvSinks()
+	}
+	closeEnvSinks = closeSinks
+
+	return err
+}
+
+// ConfigFromEnv Get DefaultConfig overridden by the LOG_* environment variables,
+// on error the returned config still holds every valid value
+func ConfigFromEnv() (Config, error) {
+	var (
+		cfg = DefaultConfig()
+		err error
+	)
+
+	if v, ok := lookupEnv(EnvLevel); ok {
+		if lvlErr := cfg.Level.UnmarshalText([]byte(v)); lvlErr != nil {
+			err = multierr.Append(err, fmt.Errorf("%s: %w", EnvLevel, lvlErr))
+		}
+	}
+
+	if v, ok := lookupEnv(EnvFormat); ok {
+		switch v = strings.ToLower(v); v {
+		case EncodingJSON, EncodingConsole:
+			cfg.Encoding = v
+		default:
+			err = multierr.Append(err, fmt.Errorf("%s: unknown format %q, expected %q or %q", EnvFormat, v, EncodingJSON, EncodingConsole))
+		}
+	}
+
+	if v, ok := lookupEnv(EnvOutput); ok {
+		cfg.OutputPaths = splitList(v)
+	}
+
+	return cfg, err
+}
+
+// lookupEnv Get a non empty trimmed environment variable
+func lookupEnv(key string) (string, bool) {
+	v := strings.TrimSpace(os.Getenv(key))
+	return v, v != ""
+}
+
+// splitList Split comma separated values skipping empty ones
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
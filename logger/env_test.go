@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv(EnvLevel, "debug")
+	t.Setenv(EnvFormat, "Console")
+	t.Setenv(EnvOutput, "stderr, /tmp/app.log,")
+
+	cfg, err := ConfigFromEnv()
+	require.NoError(t, err)
+
+	require.Equal(t, zapcore.DebugLevel, cfg.Level)
+	require.Equal(t, EncodingConsole, cfg.Encoding)
+	require.Equal(t, []string{"stderr", "/tmp/app.log"}, cfg.OutputPaths)
+}
+
+func TestConfigFromEnvInvalid(t *testing.T) {
+	t.Setenv(EnvLevel, "loud")
+	t.Setenv(EnvFormat, "xml")
+	t.Setenv(EnvOutput, "stderr")
+
+	cfg, err := ConfigFromEnv()
+	require.Error(t, err)
+	require.ErrorContains(t, err, EnvLevel)
+	require.ErrorContains(t, err, EnvFormat)
+
+	// valid values are still applied
+	require.Equal(t, DefaultConfig().Level, cfg.Level)
+	require.Equal(t, EncodingJSON, cfg.Encoding)
+	require.Equal(t, []string{"stderr"}, cfg.OutputPaths)
+}
+
+func TestParseNameLevels(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		spec    string
+		want    map[string]zapcore.Level
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			want: map[string]zapcore.Level{},
+		},
+		{
+			name: "multiple rules",
+			spec: "kafka=warn, GetApples.DB = debug",
+			want: map[string]zapcore.Level{
+				"kafka":        zapcore.WarnLevel,
+				"GetApples.DB": zapcore.DebugLevel,
+			},
+		},
+		{
+			name: "invalid rules are skipped",
+			spec: "kafka=warn,db,=info,http=loud",
+			want: map[string]zapcore.Level{
+				"kafka": zapcore.WarnLevel,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseNameLevels(tc.spec)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestSetupFromEnv(t *testing.T) {
+	prevLogger, prevLevel := Logger(), GlobalLevel().Level()
+	t.Cleanup(func() {
+		SetLogger(prevLogger)
+		SetLevel(prevLevel)
+		nameLevels.set(nil)
+		SetTraceFormatter(DefaultTraceFields())
+	})
+
+	t.Setenv(EnvLevel, "warn")
+	t.Setenv(EnvLevels, "kafka=debug")
+	t.Setenv(EnvTraceFormat, "default, xray")
+
+	require.NoError(t, setupFromEnv())
+	require.Equal(t, TraceFormatters{DefaultTraceFields(), XRayTraceFormatter{}}, CurrentTraceFormatter())
+
+	require.Equal(t, zapcore.WarnLevel, Level())
+
+	l := Logger().Desugar()
+	require.Nil(t, l.Check(zapcore.InfoLevel, "info"))
+	require.NotNil(t, l.Named("kafka").Named("consumer").Check(zapcore.DebugLevel, "debug"))
+
+	// the global logger follows SetLevel
+	SetLevel(zapcore.InfoLevel)
+	require.NotNil(t, l.Check(zapcore.InfoLevel, "info"))
+}
+
+func TestSetupFromEnvAtStartup(t *testing.T) {
+	// the child process prints the formatter set up by the init functions
+	if os.Getenv("LOGGER_TEST_STARTUP") == "1" {
+		fmt.Printf("%#v", CurrentTraceFormatter())
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSetupFromEnvAtStartup$")
+	cmd.Env = append(os.Environ(), "LOGGER_TEST_STARTUP=1", EnvTraceFormat+"=datadog", "DD_SERVICE=api", "DD_ENV=", "DD_VERSION=")
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	require.Contains(t, string(out), fmt.Sprintf("%#v", DatadogTraceFormatter{Service: "api"}))
+}
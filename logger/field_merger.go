@@ -2,6 +2,7 @@ package logger
 
 import (
 	"context"
+	"os"
 
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
@@ -10,12 +11,22 @@ import (
 
 type invalidPairs []invalidPair
 
+// mergeKvs Merges the baggage members & the fields stored by AddKV with the ones
+// passed by the caller, the result must not be modified since it can be shared with the context
 func mergeKvs(ctx context.Context, otherKVs ...any) []any {
 	kvsFromContext := getKvsFromContext(ctx)
+	if kvs := baggageKvs(ctx); len(kvs) > 0 {
+		kvsFromContext = mergeFields(kvs, kvsFromContext)
+	}
 	if len(kvsFromContext) == 0 {
 		return globalMerger.sweetenFields(otherKVs)
 	}
-	return mergeFields(kvsFromContext, globalMerger.sweetenFields(otherKVs))
+
+	fieldsFromCaller := globalMerger.sweetenFields(otherKVs)
+	if len(fieldsFromCaller) == 0 {
+		return kvsFromContext
+	}
+	return mergeFields(kvsFromContext, fieldsFromCaller)
 }
 
 type invalidPair struct {
@@ -44,7 +55,7 @@ const (
 	errMsgMultiple     = "Multiple errors without a key."
 )
 
-var globalMerger = newFieldMerger(New(zap.ErrorLevel).Desugar())
+var globalMerger = newFieldMerger(NewWithSink(zap.ErrorLevel, os.Stdout).Desugar())
 
 type fieldMerger struct {
 	logger *zap.Logger
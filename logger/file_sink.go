@@ -0,0 +1,450 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+// backupTimeFormat Rotation time in the name of the backups
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// compressSuffix Extension of the compressed backups
+const compressSuffix = ".gz"
+
+// FileSinkConfig Rotation & retention of a FileSink
+type FileSinkConfig struct {
+	// Filename file the entries are written to, the backups are kept next to it
+	// as name-2006-01-02T15-04-05.000.ext
+	Filename string
+	// MaxSize size in bytes the file is rotated at, 0 disables the size rotation
+	MaxSize int64
+	// Interval the file is rotated at every multiple of the interval (e.g. 24h),
+	// 0 disables the time rotation
+	Interval time.Duration
+	// MaxBackups number of backups kept, 0 keeps all of them
+	MaxBackups int
+	// MaxAge backups older than it are removed, 0 keeps all of them
+	MaxAge time.Duration
+	// Compress gzip the backups in the background
+	Compress bool
+	// ReopenOnSIGHUP reopen the file when the process gets SIGHUP,
+	// for the files moved by an external tool such as logrotate
+	ReopenOnSIGHUP bool
+}
+
+// FileSink zapcore.WriteSyncer writing to a file rotated by size and/or time
+//
+//	sink, err := logger.NewFileSink(logger.FileSinkConfig{Filename: "/var/log/app.log", MaxSize: 100 << 20, MaxBackups: 5, Compress: true})
+//	logger.SetLogger(logger.NewWithSink(nil, sink))
+type FileSink struct {
+	cfg FileSinkConfig
+	now func() time.Time
+
+	mu sync.Mutex
+	// file nil once a rotation or a reopen failed, the next write opens it again
+	file         *os.File
+	size         int64
+	nextRotation time.Time
+	closed       bool
+
+	// mill Wakes up the goroutine compressing & removing the backups
+	mill     chan struct{}
+	signals  chan os.Signal
+	done     chan struct{}
+	shutdown sync.WaitGroup
+}
+
+var _ zapcore.WriteSyncer = (*FileSink)(nil)
+
+// NewFileSink create a new file sink, the file is created if it doesn't exist
+// and appended to otherwise
+func NewFileSink(cfg FileSinkConfig) (*FileSink, error) {
+	return newFileSink(cfg, time.Now)
+}
+
+func newFileSink(cfg FileSinkConfig, now func() time.Time) (*FileSink, error) {
+	if cfg.Filename == "" {
+		return nil, errors.New("logger: file sink filename is required")
+	}
+	if cfg.MaxSize < 0 || cfg.Interval < 0 || cfg.MaxBackups < 0 || cfg.MaxAge < 0 {
+		return nil, errors.New("logger: file sink limits can't be negative")
+	}
+
+	s := &FileSink{
+		cfg:  cfg,
+		now:  now,
+		mill: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+
+	s.shutdown.Add(1)
+	go s.runMill()
+
+	if cfg.ReopenOnSIGHUP {
+		s.signals = make(chan os.Signal, 1)
+		signal.Notify(s.signals, syscall.SIGHUP)
+
+		s.shutdown.Add(1)
+		go s.runReopen()
+	}
+
+	// the backups left by a previous process are handled as well
+	s.wakeMill()
+	return s, nil
+}
+
+func (s *FileSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return 0, os.ErrClosed
+	}
+
+	if s.file == nil {
+		if err := s.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if s.shouldRotate(int64(len(p))) {
+		if err := s.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := s.file.Write(p)
+	s.size += int64(n)
+	return n, err
+}
+
+func (s *FileSink) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed || s.file == nil {
+		return nil
+	}
+	return s.file.Sync()
+}
+
+// Rotate Move the current file to a backup and start a new one
+func (s *FileSink) Rotate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return os.ErrClosed
+	}
+	return s.rotate()
+}
+
+// Reopen Close & open the file again, to be called once it was moved away
+func (s *FileSink) Reopen() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return os.ErrClosed
+	}
+
+	var err error
+	if s.file != nil {
+		err = s.file.Close()
+		s.file = nil
+	}
+	return multierr.Append(err, s.open())
+}
+
+// Close Close the file and wait for the pending compression & cleanup
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	var err error
+	if s.file != nil {
+		err = s.file.Close()
+	}
+	s.mu.Unlock()
+
+	if s.signals != nil {
+		signal.Stop(s.signals)
+	}
+	close(s.done)
+	s.shutdown.Wait()
+
+	return err
+}
+
+// shouldRotate Must be called with the lock held
+func (s *FileSink) shouldRotate(n int64) bool {
+	if s.cfg.MaxSize > 0 && s.size > 0 && s.size+n > s.cfg.MaxSize {
+		return true
+	}
+	return s.cfg.Interval > 0 && !s.now().Before(s.nextRotation)
+}
+
+// rotate Must be called with the lock held, on error the file is either
+// the current one opened again or nil to be opened by the next write
+func (s *FileSink) rotate() error {
+	err := s.file.Close()
+	s.file = nil
+	if err != nil {
+		return multierr.Append(fmt.Errorf("logger: close log file: %w", err), s.open())
+	}
+
+	backup := s.backupName(s.now())
+	if err := os.Rename(s.cfg.Filename, backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		// the entries keep going to the current file
+		return multierr.Append(fmt.Errorf("logger: rotate log file: %w", err), s.open())
+	}
+
+	if err := s.open(); err != nil {
+		return err
+	}
+
+	s.wakeMill()
+	return nil
+}
+
+// open Open the file for appending, must be called with the lock held
+func (s *FileSink) open() error {
+	if err := os.MkdirAll(filepath.Dir(s.cfg.Filename), 0o755); err != nil {
+		return fmt.Errorf("logger: create log directory: %w", err)
+	}
+
+	f, err := os.OpenFile(s.cfg.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("logger: open log file: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("logger: stat log file: %w", err)
+	}
+
+	s.file = f
+	s.size = info.Size()
+	if s.cfg.Interval > 0 {
+		s.nextRotation = s.now().Truncate(s.cfg.Interval).Add(s.cfg.Interval)
+	}
+	return nil
+}
+
+// backupName Get a backup name not used yet for the rotation time
+func (s *FileSink) backupName(t time.Time) string {
+	prefix, ext := s.backupPrefixExt()
+	name := prefix + t.UTC().Format(backupTimeFormat)
+
+	backup := name + ext
+	for i := 1; fileExists(backup) || fileExists(backup+compressSuffix); i++ {
+		backup = fmt.Sprintf("%s.%d%s", name, i, ext)
+	}
+	return backup
+}
+
+// backupPrefixExt Get the path prefix & the extension shared by the backups
+func (s *FileSink) backupPrefixExt() (string, string) {
+	ext := filepath.Ext(s.cfg.Filename)
+	return strings.TrimSuffix(s.cfg.Filename, ext) + "-", ext
+}
+
+func (s *FileSink) wakeMill() {
+	select {
+	case s.mill <- struct{}{}:
+	default:
+	}
+}
+
+func (s *FileSink) runMill() {
+	defer s.shutdown.Done()
+
+	for {
+		select {
+		case <-s.mill:
+			if err := s.millBackups(); err != nil {
+				fmt.Fprintf(os.Stderr, "logger: file sink backups: %v\n", err)
+			}
+		case <-s.done:
+			// the last rotation may still be pending
+			select {
+			case <-s.mill:
+				if err := s.millBackups(); err != nil {
+					fmt.Fprintf(os.Stderr, "logger: file sink backups: %v\n", err)
+				}
+			default:
+			}
+			return
+		}
+	}
+}
+
+func (s *FileSink) runReopen() {
+	defer s.shutdown.Done()
+
+	for {
+		select {
+		case <-s.signals:
+			if err := s.Reopen(); err != nil && !errors.Is(err, os.ErrClosed) {
+				fmt.Fprintf(os.Stderr, "logger: file sink reopen: %v\n", err)
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// fileBackup Rotated file
+type fileBackup struct {
+	path      string
+	rotatedAt time.Time
+	// seq Suffix of the backups rotated within the same millisecond
+	seq        int
+	compressed bool
+}
+
+// millBackups Remove the backups out of the retention and compress the others
+func (s *FileSink) millBackups() error {
+	backups, err := s.backups()
+	if err != nil {
+		return err
+	}
+
+	var cutoff time.Time
+	if s.cfg.MaxAge > 0 {
+		cutoff = s.now().Add(-s.cfg.MaxAge)
+	}
+
+	for i, b := range backups {
+		expired := s.cfg.MaxBackups > 0 && i >= s.cfg.MaxBackups
+		if !cutoff.IsZero() && b.rotatedAt.Before(cutoff) {
+			expired = true
+		}
+
+		switch {
+		case expired:
+			err = multierr.Append(err, os.Remove(b.path))
+		case s.cfg.Compress && !b.compressed:
+			err = multierr.Append(err, compressFile(b.path))
+		}
+	}
+
+	return err
+}
+
+// backups Get the backups of the file sorted from the newest one
+func (s *FileSink) backups() ([]fileBackup, error) {
+	entries, err := os.ReadDir(filepath.Dir(s.cfg.Filename))
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, ext := s.backupPrefixExt()
+	prefix = filepath.Base(prefix)
+
+	var backups []fileBackup
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		name := e.Name()
+		b := fileBackup{path: filepath.Join(filepath.Dir(s.cfg.Filename), name)}
+		if strings.HasSuffix(name, compressSuffix) {
+			name, b.compressed = strings.TrimSuffix(name, compressSuffix), true
+		}
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		if len(stamp) < len(backupTimeFormat) {
+			continue
+		}
+		t, err := time.Parse(backupTimeFormat, stamp[:len(backupTimeFormat)])
+		if err != nil {
+			continue
+		}
+		b.rotatedAt = t
+
+		if seq := stamp[len(backupTimeFormat):]; seq != "" {
+			if b.seq, err = strconv.Atoi(strings.TrimPrefix(seq, ".")); err != nil || seq[0] != '.' {
+				continue
+			}
+		}
+
+		backups = append(backups, b)
+	}
+
+	sort.SliceStable(backups, func(i, j int) bool {
+		if !backups[i].rotatedAt.Equal(backups[j].rotatedAt) {
+			return backups[i].rotatedAt.After(backups[j].rotatedAt)
+		}
+		return backups[i].seq > backups[j].seq
+	})
+	return backups, nil
+}
+
+// compressFile Replace the file with its gzipped copy
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp := path + compressSuffix + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp)
+		}
+	}()
+
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Rename(tmp, path+compressSuffix); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,300 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// testClock Clock moved manually by the tests
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newTestClock() *testClock {
+	return &testClock{now: time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)}
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestFileSinkRotation(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		cfg     FileSinkConfig
+		writes  []string
+		advance time.Duration
+		backups []string
+		current string
+	}{
+		{
+			name:    "size",
+			cfg:     FileSinkConfig{MaxSize: 10},
+			writes:  []string{"first\n", "second\n", "third\n"},
+			backups: []string{"first\n", "second\n"},
+			current: "third\n",
+		},
+		{
+			name:    "write bigger than the max size",
+			cfg:     FileSinkConfig{MaxSize: 4},
+			writes:  []string{"first\n", "second\n"},
+			backups: []string{"first\n"},
+			current: "second\n",
+		},
+		{
+			name:    "interval",
+			cfg:     FileSinkConfig{Interval: time.Hour},
+			writes:  []string{"first\n", "second\n", "third\n"},
+			advance: 20 * time.Minute,
+			backups: []string{"first\nsecond\n"},
+			current: "third\n",
+		},
+		{
+			name:    "no rotation",
+			cfg:     FileSinkConfig{},
+			writes:  []string{"first\n", "second\n"},
+			advance: 24 * time.Hour,
+			current: "first\nsecond\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			clock := newTestClock()
+
+			tc.cfg.Filename = filepath.Join(dir, "app.log")
+			s, err := newFileSink(tc.cfg, clock.Now)
+			require.NoError(t, err)
+
+			for _, w := range tc.writes {
+				_, err := s.Write([]byte(w))
+				require.NoError(t, err)
+				clock.Add(tc.advance)
+			}
+			require.NoError(t, s.Close())
+
+			require.Equal(t, tc.backups, readBackups(t, tc.cfg.Filename))
+			require.Equal(t, tc.current, readFile(t, tc.cfg.Filename))
+		})
+	}
+}
+
+func TestFileSinkRetention(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		cfg     FileSinkConfig
+		backups []string
+	}{
+		{
+			name:    "keep all",
+			cfg:     FileSinkConfig{},
+			backups: []string{"1\n", "2\n", "3\n", "4\n"},
+		},
+		{
+			name:    "max backups",
+			cfg:     FileSinkConfig{MaxBackups: 2},
+			backups: []string{"3\n", "4\n"},
+		},
+		{
+			name:    "max age",
+			cfg:     FileSinkConfig{MaxAge: 30 * time.Minute},
+			backups: []string{"4\n"},
+		},
+		{
+			name:    "compressed",
+			cfg:     FileSinkConfig{MaxBackups: 3, Compress: true},
+			backups: []string{"2\n", "3\n", "4\n"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			clock := newTestClock()
+			tc.cfg.Filename = filepath.Join(t.TempDir(), "app.log")
+			s, err := newFileSink(tc.cfg, clock.Now)
+			require.NoError(t, err)
+
+			for _, w := range []string{"1\n", "2\n", "3\n", "4\n"} {
+				_, err := s.Write([]byte(w))
+				require.NoError(t, err)
+				clock.Add(time.Hour)
+				require.NoError(t, s.Rotate())
+			}
+			_, err = s.Write([]byte("current\n"))
+			require.NoError(t, err)
+			require.NoError(t, s.Close())
+
+			require.Equal(t, tc.backups, readBackups(t, tc.cfg.Filename))
+			require.Equal(t, "current\n", readFile(t, tc.cfg.Filename))
+
+			if tc.cfg.Compress {
+				matches, err := filepath.Glob(filepath.Join(filepath.Dir(tc.cfg.Filename), "app-*.log"))
+				require.NoError(t, err)
+				require.Empty(t, matches)
+			}
+		})
+	}
+}
+
+func TestFileSinkSameTimeRotation(t *testing.T) {
+	t.Parallel()
+
+	clock := newTestClock()
+	filename := filepath.Join(t.TempDir(), "app")
+	s, err := newFileSink(FileSinkConfig{Filename: filename, MaxSize: 2}, clock.Now)
+	require.NoError(t, err)
+
+	for _, w := range []string{"1\n", "2\n", "3\n", "4\n"} {
+		_, err := s.Write([]byte(w))
+		require.NoError(t, err)
+	}
+	require.NoError(t, s.Close())
+
+	require.Equal(t, []string{"1\n", "2\n", "3\n"}, readBackups(t, filename))
+}
+
+func TestFileSinkReopen(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), "logs", "app.log")
+	s, err := NewFileSink(FileSinkConfig{Filename: filename})
+	require.NoError(t, err)
+
+	l := NewWithSink(nil, s, WithLevel(zapcore.DebugLevel))
+	l.Info("before")
+
+	// logrotate moves the file away then notifies the process
+	require.NoError(t, os.Rename(filename, filename+".1"))
+	l.Info("moved")
+	require.NoError(t, s.Reopen())
+	l.Info("after")
+	require.NoError(t, s.Close())
+
+	_, err = s.Write([]byte("closed"))
+	require.ErrorIs(t, err, os.ErrClosed)
+
+	rotated := readFile(t, filename+".1")
+	require.Contains(t, rotated, "before")
+	require.Contains(t, rotated, "moved")
+
+	current := readFile(t, filename)
+	require.Contains(t, current, "after")
+	require.NotContains(t, current, "before")
+}
+
+func TestFileSinkRotationFailure(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "logs")
+	filename := filepath.Join(dir, "app.log")
+	s, err := NewFileSink(FileSinkConfig{Filename: filename, MaxSize: 8})
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, s.Close()) })
+
+	_, err = s.Write([]byte("first\n"))
+	require.NoError(t, err)
+
+	// the log directory is briefly replaced by a file
+	require.NoError(t, os.RemoveAll(dir))
+	require.NoError(t, os.WriteFile(dir, nil, 0o644))
+
+	_, err = s.Write([]byte("second\n"))
+	require.Error(t, err)
+	require.NoError(t, s.Sync())
+
+	// the writes recover once the directory is back
+	require.NoError(t, os.Remove(dir))
+	_, err = s.Write([]byte("third\n"))
+	require.NoError(t, err)
+	require.Equal(t, "third\n", readFile(t, filename))
+
+	// a failed reopen is recovered by the next one
+	require.NoError(t, os.RemoveAll(dir))
+	require.NoError(t, os.WriteFile(dir, nil, 0o644))
+	require.Error(t, s.Reopen())
+	require.NoError(t, os.Remove(dir))
+	require.NoError(t, s.Reopen())
+	_, err = s.Write([]byte("fourth\n"))
+	require.NoError(t, err)
+	require.Equal(t, "fourth\n", readFile(t, filename))
+}
+
+func TestNewFileSinkInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewFileSink(FileSinkConfig{})
+	require.Error(t, err)
+
+	_, err = NewFileSink(FileSinkConfig{Filename: filepath.Join(t.TempDir(), "app.log"), MaxBackups: -1})
+	require.Error(t, err)
+}
+
+// readBackups Read the backups of the file from the oldest one, decompressing them
+func readBackups(t *testing.T, filename string) []string {
+	t.Helper()
+
+	ext := filepath.Ext(filename)
+	matches, err := filepath.Glob(strings.TrimSuffix(filename, ext) + "-*")
+	require.NoError(t, err)
+
+	s := &FileSink{cfg: FileSinkConfig{Filename: filename}}
+	backups, err := s.backups()
+	require.NoError(t, err)
+	require.Len(t, backups, len(matches))
+
+	var contents []string
+	for i := len(backups) - 1; i >= 0; i-- {
+		if !backups[i].compressed {
+			contents = append(contents, readFile(t, backups[i].path))
+			continue
+		}
+
+		f, err := os.Open(backups[i].path)
+		require.NoError(t, err)
+		zr, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		data, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		contents = append(contents, string(data))
+	}
+	return contents
+}
+
+func readFile(t *testing.T, filename string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	return string(data)
+}
@@ -0,0 +1,32 @@
+//go:build unix
+
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSinkReopenOnSIGHUP(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	s, err := NewFileSink(FileSinkConfig{Filename: filename, ReopenOnSIGHUP: true})
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, s.Close()) })
+
+	require.NoError(t, os.Rename(filename, filename+".1"))
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(filename)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = s.Write([]byte("after\n"))
+	require.NoError(t, err)
+	require.Equal(t, "after\n", readFile(t, filename))
+}
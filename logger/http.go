@@ -0,0 +1,285 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// LevelHandler HTTP handler to inspect and change the log levels at runtime.
+//
+// The global log_level is set at the handler path & the level the global logger
+// logs at is served there, a logger name rule (see SetNameLevel) is selected
+// with the "logger" query parameter:
+//
+//	GET    /                             -> {"level":"error","loggers":{"kafka":{"level":"warn"}}}
+//	PUT    /  {"level":"debug","duration":"10m"}
+//	GET    /?logger=GetApples.AppleManager.DB
+//	PUT    /?logger=GetApples.*.DB  {"level":"debug","duration":"10m"}
+//	DELETE /?logger=GetApples.*.DB
+//
+// A level set with a duration is reverted to its previous value once it expires.
+type LevelHandler struct {
+	mu      sync.Mutex
+	reverts map[string]*levelRevert
+}
+
+// levelRevert Pending revert of a time-boxed level change
+type levelRevert struct {
+	timer     *time.Timer
+	expiresAt time.Time
+	// level previous level, nil if there was no rule for the logger
+	level *zapcore.Level
+}
+
+type levelRequest struct {
+	Level    *zapcore.Level `json:"level"`
+	Duration string         `json:"duration,omitempty"`
+}
+
+type levelResponse struct {
+	Logger    string                   `json:"logger,omitempty"`
+	Level     zapcore.Level            `json:"level"`
+	Rule      bool                     `json:"rule,omitempty"`
+	ExpiresAt *time.Time               `json:"expires_at,omitempty"`
+	Loggers   map[string]levelResponse `json:"loggers,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// globalRevertKey Key of the global log_level in the reverts
+const globalRevertKey = ""
+
+// maxLevelRequestSize Size limit of the PUT request bodies, larger ones are rejected with 413
+const maxLevelRequestSize = 4 << 10
+
+// NewLevelHandler create a new handler to manage the log levels over HTTP
+func NewLevelHandler() *LevelHandler {
+	return &LevelHandler{
+		reverts: make(map[string]*levelRevert),
+	}
+}
+
+func (h *LevelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, named := r.URL.Query().Get("logger"), r.URL.Query().Has("logger")
+	if named && name == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse{"empty logger name"})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if named {
+			writeJSON(w, http.StatusOK, h.loggerLevel(name))
+			return
+		}
+		writeJSON(w, http.StatusOK, h.globalLevel())
+
+	case http.MethodPut:
+		r.Body = http.MaxBytesReader(w, r.Body, maxLevelRequestSize)
+		lvl, duration, err := decodeLevelRequest(r)
+		if err != nil {
+			status := http.StatusBadRequest
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			writeJSON(w, status, errorResponse{err.Error()})
+			return
+		}
+
+		if named {
+			if err := h.setLoggerLevel(name, lvl, duration); err != nil {
+				writeJSON(w, http.StatusBadRequest, errorResponse{err.Error()})
+				return
+			}
+			writeJSON(w, http.StatusOK, h.loggerLevel(name))
+			return
+		}
+		h.setGlobalLevel(lvl, duration)
+		writeJSON(w, http.StatusOK, h.globalLevel())
+
+	case http.MethodDelete:
+		if !named {
+			writeJSON(w, http.StatusBadRequest, errorResponse{"the global level can't be deleted"})
+			return
+		}
+		h.unsetLoggerLevel(name)
+		writeJSON(w, http.StatusOK, h.loggerLevel(name))
+
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{fmt.Sprintf("method %s not allowed", r.Method)})
+	}
+}
+
+func decodeLevelRequest(r *http.Request) (zapcore.Level, time.Duration, error) {
+	var req levelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return zapcore.InvalidLevel, 0, fmt.Errorf("invalid request body: %w", err)
+	}
+	if req.Level == nil {
+		return zapcore.InvalidLevel, 0, errors.New("level is required")
+	}
+
+	var duration time.Duration
+	if req.Duration != "" {
+		var err error
+		if duration, err = time.ParseDuration(req.Duration); err != nil {
+			return zapcore.InvalidLevel, 0, fmt.Errorf("invalid duration: %w", err)
+		}
+		if duration <= 0 {
+			return zapcore.InvalidLevel, 0, errors.New("duration must be positive")
+		}
+	}
+
+	return *req.Level, duration, nil
+}
+
+func (h *LevelHandler) globalLevel() levelResponse {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	resp := levelResponse{
+		Level:     Level(),
+		ExpiresAt: h.expiresAt(globalRevertKey),
+	}
+
+	if rules := NameLevels(); len(rules) > 0 {
+		resp.Loggers = make(map[string]levelResponse, len(rules))
+		for pattern, lvl := range rules {
+			resp.Loggers[pattern] = levelResponse{
+				Level:     lvl,
+				ExpiresAt: h.expiresAt(pattern),
+			}
+		}
+	}
+
+	return resp
+}
+
+// loggerLevel Get the effective level of the logger name
+// or of the rule if the name is a pattern
+func (h *LevelHandler) loggerLevel(name string) levelResponse {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	resp := levelResponse{
+		Logger: name,
+		Level:  EffectiveLevel(name),
+	}
+
+	if lvl, ok := NameLevels()[name]; ok {
+		resp.Level, resp.Rule = lvl, true
+		resp.ExpiresAt = h.expiresAt(name)
+	} else if lvl, ok := NameLevel(name); ok {
+		resp.Level, resp.Rule = lvl, true
+	}
+
+	return resp
+}
+
+func (h *LevelHandler) setGlobalLevel(lvl zapcore.Level, duration time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	prev := GlobalLevel().Level()
+	h.scheduleRevert(globalRevertKey, &prev, duration, func(prev *zapcore.Level) {
+		SetLevel(*prev)
+	})
+	SetLevel(lvl)
+}
+
+func (h *LevelHandler) setLoggerLevel(pattern string, lvl zapcore.Level, duration time.Duration) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var prev *zapcore.Level
+	if prevLvl, ok := NameLevels()[pattern]; ok {
+		prev = &prevLvl
+	}
+
+	if err := SetNameLevel(pattern, lvl); err != nil {
+		return err
+	}
+
+	h.scheduleRevert(pattern, prev, duration, func(prev *zapcore.Level) {
+		if prev == nil {
+			UnsetNameLevel(pattern)
+			return
+		}
+		_ = SetNameLevel(pattern, *prev)
+	})
+	return nil
+}
+
+func (h *LevelHandler) unsetLoggerLevel(pattern string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.cancelRevert(pattern)
+	UnsetNameLevel(pattern)
+}
+
+// scheduleRevert Revert the level once the duration expires,
+// a zero duration makes the change permanent.
+// Repeated time-boxed changes revert to the level before the first one.
+// Must be called with the lock held.
+func (h *LevelHandler) scheduleRevert(key string, prev *zapcore.Level, duration time.Duration, revert func(prev *zapcore.Level)) {
+	if pending, ok := h.reverts[key]; ok {
+		pending.timer.Stop()
+		delete(h.reverts, key)
+		prev = pending.level
+	}
+
+	if duration <= 0 {
+		return
+	}
+
+	pending := &levelRevert{
+		expiresAt: time.Now().Add(duration),
+		level:     prev,
+	}
+	pending.timer = time.AfterFunc(duration, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		// the revert was replaced or canceled meanwhile
+		if h.reverts[key] != pending {
+			return
+		}
+		delete(h.reverts, key)
+		revert(pending.level)
+	})
+	h.reverts[key] = pending
+}
+
+// cancelRevert Must be called with the lock held
+func (h *LevelHandler) cancelRevert(key string) {
+	if pending, ok := h.reverts[key]; ok {
+		pending.timer.Stop()
+		delete(h.reverts, key)
+	}
+}
+
+// expiresAt Must be called with the lock held
+func (h *LevelHandler) expiresAt(key string) *time.Time {
+	if pending, ok := h.reverts[key]; ok {
+		t := pending.expiresAt
+		return &t
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,162 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLevelHandlerGlobal(t *testing.T) {
+	prevLevel := GlobalLevel().Level()
+	t.Cleanup(func() {
+		SetLevel(prevLevel)
+		nameLevels.set(nil)
+	})
+	SetLevel(zapcore.ErrorLevel)
+	require.NoError(t, SetNameLevel("kafka", zapcore.WarnLevel))
+
+	h := NewLevelHandler()
+
+	got := serveLevelHandler(t, h, http.MethodGet, "/", "", http.StatusOK)
+	require.Equal(t, "error", got["level"])
+	require.Equal(t, map[string]interface{}{"kafka": map[string]interface{}{"level": "warn"}}, got["loggers"])
+
+	got = serveLevelHandler(t, h, http.MethodPut, "/", `{"level":"debug"}`, http.StatusOK)
+	require.Equal(t, "debug", got["level"])
+	require.Equal(t, zapcore.DebugLevel, Level())
+	require.NotContains(t, got, "expires_at")
+}
+
+func TestLevelHandlerEffectiveLevel(t *testing.T) {
+	prev, prevLevel := Logger(), GlobalLevel().Level()
+	t.Cleanup(func() {
+		SetLogger(prev)
+		SetLevel(prevLevel)
+		nameLevels.set(nil)
+	})
+	SetLogger(NewWithSink(zapcore.WarnLevel, io.Discard))
+
+	h := NewLevelHandler()
+
+	// the global logger doesn't follow the global level
+	got := serveLevelHandler(t, h, http.MethodPut, "/", `{"level":"debug"}`, http.StatusOK)
+	require.Equal(t, "warn", got["level"])
+	require.Equal(t, zapcore.DebugLevel, GlobalLevel().Level())
+
+	got = serveLevelHandler(t, h, http.MethodGet, "/?logger=db", "", http.StatusOK)
+	require.Equal(t, "warn", got["level"])
+}
+
+func TestLevelHandlerLogger(t *testing.T) {
+	t.Cleanup(func() { nameLevels.set(nil) })
+
+	h := NewLevelHandler()
+
+	got := serveLevelHandler(t, h, http.MethodPut, "/?logger=GetApples.*.DB", `{"level":"debug"}`, http.StatusOK)
+	require.Equal(t, "GetApples.*.DB", got["logger"])
+	require.Equal(t, "debug", got["level"])
+	require.Equal(t, true, got["rule"])
+
+	// effective level of a logger matching the rule
+	got = serveLevelHandler(t, h, http.MethodGet, "/?logger=GetApples.AppleManager.DB", "", http.StatusOK)
+	require.Equal(t, "debug", got["level"])
+	require.Equal(t, true, got["rule"])
+
+	got = serveLevelHandler(t, h, http.MethodDelete, "/?logger=GetApples.*.DB", "", http.StatusOK)
+	require.NotContains(t, got, "rule")
+	require.Empty(t, NameLevels())
+}
+
+func TestLevelHandlerTimeBoxed(t *testing.T) {
+	prevLevel := GlobalLevel().Level()
+	t.Cleanup(func() {
+		SetLevel(prevLevel)
+		nameLevels.set(nil)
+	})
+	SetLevel(zapcore.ErrorLevel)
+	require.NoError(t, SetNameLevel("kafka", zapcore.WarnLevel))
+
+	h := NewLevelHandler()
+
+	got := serveLevelHandler(t, h, http.MethodPut, "/", `{"level":"debug","duration":"50ms"}`, http.StatusOK)
+	require.Contains(t, got, "expires_at")
+	serveLevelHandler(t, h, http.MethodPut, "/?logger=kafka", `{"level":"debug","duration":"50ms"}`, http.StatusOK)
+	serveLevelHandler(t, h, http.MethodPut, "/?logger=db", `{"level":"info","duration":"50ms"}`, http.StatusOK)
+
+	// a repeated elevation still reverts to the original level
+	serveLevelHandler(t, h, http.MethodPut, "/", `{"level":"info","duration":"50ms"}`, http.StatusOK)
+
+	require.Equal(t, zapcore.InfoLevel, Level())
+	require.Equal(t, map[string]zapcore.Level{"kafka": zapcore.DebugLevel, "db": zapcore.InfoLevel}, NameLevels())
+
+	require.Eventually(t, func() bool {
+		return Level() == zapcore.ErrorLevel && len(NameLevels()) == 1
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, map[string]zapcore.Level{"kafka": zapcore.WarnLevel}, NameLevels())
+
+	got = serveLevelHandler(t, h, http.MethodGet, "/", "", http.StatusOK)
+	require.NotContains(t, got, "expires_at")
+}
+
+func TestLevelHandlerErrors(t *testing.T) {
+	t.Parallel()
+
+	h := NewLevelHandler()
+
+	cases := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		status int
+	}{
+		{name: "invalid body", method: http.MethodPut, target: "/", body: "{", status: http.StatusBadRequest},
+		{name: "missing level", method: http.MethodPut, target: "/", body: `{}`, status: http.StatusBadRequest},
+		{name: "invalid level", method: http.MethodPut, target: "/", body: `{"level":"loud"}`, status: http.StatusBadRequest},
+		{name: "invalid duration", method: http.MethodPut, target: "/", body: `{"level":"info","duration":"soon"}`, status: http.StatusBadRequest},
+		{name: "negative duration", method: http.MethodPut, target: "/", body: `{"level":"info","duration":"-1m"}`, status: http.StatusBadRequest},
+		{name: "invalid pattern", method: http.MethodPut, target: "/?logger=a..b", body: `{"level":"info"}`, status: http.StatusBadRequest},
+		{name: "empty logger", method: http.MethodGet, target: "/?logger=", status: http.StatusBadRequest},
+		{name: "delete global", method: http.MethodDelete, target: "/", status: http.StatusBadRequest},
+		{name: "unsupported method", method: http.MethodPost, target: "/", status: http.StatusMethodNotAllowed},
+		{
+			name:   "body too large",
+			method: http.MethodPut,
+			target: "/",
+			body:   `{"level":"info","duration":"` + strings.Repeat("1", maxLevelRequestSize) + `s"}`,
+			status: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := serveLevelHandler(t, h, tc.method, tc.target, tc.body, tc.status)
+			require.NotEmpty(t, got["error"])
+		})
+	}
+}
+
+func serveLevelHandler(t *testing.T, h http.Handler, method, target, body string, wantStatus int) map[string]interface{} {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+
+	require.Equal(t, wantStatus, rec.Code, rec.Body.String())
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &decoded))
+	return decoded
+}
@@ -0,0 +1,385 @@
+package logger
+
+import (
+	"fmt"
+	"hash/maphash"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	// nameLevels Per logger name log_level overrides
+	nameLevels levelRules
+
+	sharedLevelsMu sync.Mutex
+	sharedLevels   = make(map[string]zap.AtomicLevel)
+)
+
+// GlobalLevel Get the log_level followed by the global logger,
+// the loggers bound to it follow SetLevel
+func GlobalLevel() zap.AtomicLevel {
+	return defaultLevel
+}
+
+// SharedLevel Get the log_level shared by every logger bound to the name,
+// created at the current global log_level on first use.
+// The empty name is the global log_level.
+//
+//	db := logger.New(logger.SharedLevel("db"))
+//	logger.SharedLevel("db").SetLevel(zapcore.DebugLevel)
+func SharedLevel(name string) zap.AtomicLevel {
+	if name == "" {
+		return defaultLevel
+	}
+
+	sharedLevelsMu.Lock()
+	defer sharedLevelsMu.Unlock()
+
+	lvl, ok := sharedLevels[name]
+	if !ok {
+		lvl = zap.NewAtomicLevelAt(defaultLevel.Level())
+		sharedLevels[name] = lvl
+	}
+	return lvl
+}
+
+// SetNameLevel Set the log_level of the loggers whose name matches the pattern.
+//
+// The pattern is a dotted logger name (as built by WithName) where every
+// segment can be a glob (e.g. "GetApples.*.DB"). A pattern also matches the
+// children of the loggers it names, so "kafka" matches "kafka.consumer".
+// When several patterns match, the most specific one wins: the one with
+// more segments, then the one with fewer wildcard segments.
+func SetNameLevel(pattern string, lvl zapcore.Level) error {
+	if err := validateLevelPattern(pattern); err != nil {
+		return err
+	}
+
+	nameLevels.update(func(rules map[string]zapcore.Level) {
+		rules[pattern] = lvl
+	})
+	return nil
+}
+
+// UnsetNameLevel Remove the log_level override set for the pattern
+func UnsetNameLevel(pattern string) {
+	nameLevels.update(func(rules map[string]zapcore.Level) {
+		delete(rules, pattern)
+	})
+}
+
+// SetNameLevels Replace all the overrides with the ones from the spec
+// in the "GetApples.*.DB=debug,kafka=warn" format, nothing is changed on error
+func SetNameLevels(spec string) error {
+	levels, err := parseNameLevels(spec)
+	if err != nil {
+		return err
+	}
+
+	nameLevels.set(levels)
+	return nil
+}
+
+// NameLevels Get a copy of the overrides by pattern
+func NameLevels() map[string]zapcore.Level {
+	return nameLevels.snapshot().levels()
+}
+
+// NameLevel Get the log_level override applied to the logger name
+func NameLevel(name string) (zapcore.Level, bool) {
+	return nameLevels.levelFor(name)
+}
+
+// parseNameLevels Parse per logger name overrides
+// in the "name=level,other.*.name=level" format,
+// on error the valid rules are still returned
+func parseNameLevels(spec string) (map[string]zapcore.Level, error) {
+	var (
+		levels = make(map[string]zapcore.Level)
+		err    error
+	)
+
+	for _, rule := range splitList(spec) {
+		pattern, lvlText, ok := strings.Cut(rule, "=")
+		pattern = strings.TrimSpace(pattern)
+		if !ok || pattern == "" {
+			err = multierr.Append(err, fmt.Errorf("invalid rule %q, expected name=level", rule))
+			continue
+		}
+
+		if patternErr := validateLevelPattern(pattern); patternErr != nil {
+			err = multierr.Append(err, patternErr)
+			continue
+		}
+
+		var lvl zapcore.Level
+		if lvlErr := lvl.UnmarshalText([]byte(strings.TrimSpace(lvlText))); lvlErr != nil {
+			err = multierr.Append(err, fmt.Errorf("rule %q: %w", rule, lvlErr))
+			continue
+		}
+
+		levels[pattern] = lvl
+	}
+
+	return levels, err
+}
+
+// levelRules Log levels by logger name pattern
+type levelRules struct {
+	mu    sync.Mutex
+	rules atomic.Pointer[levelRuleSet]
+}
+
+// levelRuleSet Immutable set of rules sorted from the most specific one
+type levelRuleSet struct {
+	rules []levelRule
+	min   zapcore.Level
+
+	// matches Direct mapped cache of the rules resolved by logger name,
+	// bounded like loggerCache so dynamically built names can't grow it
+	matches [nameMatchCacheSize]atomic.Pointer[nameMatch]
+}
+
+// nameMatchCacheSize Number of slots of the resolved rules cache of a rule set
+const nameMatchCacheSize = 256
+
+var nameMatchSeed = maphash.MakeSeed()
+
+// nameMatch Rule index resolved for a logger name, -1 if none matches
+type nameMatch struct {
+	name  string
+	index int
+}
+
+type levelRule struct {
+	pattern   string
+	segments  []string
+	wildcards int
+	level     zapcore.Level
+}
+
+func (r *levelRules) snapshot() *levelRuleSet {
+	if set := r.rules.Load(); set != nil {
+		return set
+	}
+	return &levelRuleSet{}
+}
+
+func (r *levelRules) set(rules map[string]zapcore.Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rules.Store(newLevelRuleSet(rules))
+}
+
+func (r *levelRules) update(fn func(rules map[string]zapcore.Level)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rules := r.snapshot().levels()
+	fn(rules)
+	r.rules.Store(newLevelRuleSet(rules))
+}
+
+// levelFor Get the level of the most specific rule matching the logger name
+func (r *levelRules) levelFor(name string) (zapcore.Level, bool) {
+	set := r.rules.Load()
+	if set == nil || len(set.rules) == 0 || name == "" {
+		return zapcore.InvalidLevel, false
+	}
+
+	slot := &set.matches[maphash.String(nameMatchSeed, name)%nameMatchCacheSize]
+	match := slot.Load()
+	if match == nil || match.name != name {
+		match = &nameMatch{name: name, index: set.match(name)}
+		slot.Store(match)
+	}
+
+	if match.index >= 0 {
+		return set.rules[match.index].level, true
+	}
+	return zapcore.InvalidLevel, false
+}
+
+// minLevel Get the lowest level among the rules
+func (r *levelRules) minLevel() (zapcore.Level, bool) {
+	set := r.rules.Load()
+	if set == nil || len(set.rules) == 0 {
+		return zapcore.InvalidLevel, false
+	}
+	return set.min, true
+}
+
+func newLevelRuleSet(levels map[string]zapcore.Level) *levelRuleSet {
+	set := &levelRuleSet{
+		rules: make([]levelRule, 0, len(levels)),
+		min:   zapcore.InvalidLevel,
+	}
+
+	for pattern, lvl := range levels {
+		rule := levelRule{
+			pattern:  pattern,
+			segments: strings.Split(pattern, "."),
+			level:    lvl,
+		}
+		for _, s := range rule.segments {
+			if isGlob(s) {
+				rule.wildcards++
+			}
+		}
+
+		set.rules = append(set.rules, rule)
+		if set.min == zapcore.InvalidLevel || lvl < set.min {
+			set.min = lvl
+		}
+	}
+
+	sort.Slice(set.rules, func(i, j int) bool {
+		a, b := set.rules[i], set.rules[j]
+		if len(a.segments) != len(b.segments) {
+			return len(a.segments) > len(b.segments)
+		}
+		if a.wildcards != b.wildcards {
+			return a.wildcards < b.wildcards
+		}
+		return a.pattern < b.pattern
+	})
+
+	return set
+}
+
+func (s *levelRuleSet) levels() map[string]zapcore.Level {
+	levels := make(map[string]zapcore.Level, len(s.rules))
+	for _, rule := range s.rules {
+		levels[rule.pattern] = rule.level
+	}
+	return levels
+}
+
+// match Get the index of the first (most specific) rule matching the name
+func (s *levelRuleSet) match(name string) int {
+	segments := strings.Split(name, ".")
+
+	for i, rule := range s.rules {
+		if rule.matches(segments) {
+			return i
+		}
+	}
+	return -1
+}
+
+// matches Check whether the rule matches the name or one of its parents
+func (r levelRule) matches(name []string) bool {
+	if len(r.segments) > len(name) {
+		return false
+	}
+
+	for i, s := range r.segments {
+		if s == name[i] {
+			continue
+		}
+		if ok, _ := path.Match(s, name[i]); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func isGlob(segment string) bool {
+	return strings.ContainsAny(segment, "*?[")
+}
+
+// validateLevelPattern Check the pattern has no empty or malformed segments
+func validateLevelPattern(pattern string) error {
+	for _, s := range strings.Split(pattern, ".") {
+		if s == "" {
+			return fmt.Errorf("logger: invalid name pattern %q: empty segment", pattern)
+		}
+		if _, err := path.Match(s, ""); err != nil {
+			return fmt.Errorf("logger: invalid name pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// nameLevelCore Core deciding whether an entry is logged
+// by the per logger name overrides, falling back to its own level
+type nameLevelCore struct {
+	zapcore.Core
+	level zapcore.LevelEnabler
+}
+
+func newNameLevelCore(core zapcore.Core, level zapcore.LevelEnabler) zapcore.Core {
+	return &nameLevelCore{core, level}
+}
+
+// Level Get the lowest level an entry can be logged at,
+// the actual one depends on the name of the logger
+func (c *nameLevelCore) Level() zapcore.Level {
+	lvl := zapcore.LevelOf(c.level)
+	if ruleLvl, ok := nameLevels.minLevel(); ok && ruleLvl < lvl {
+		return ruleLvl
+	}
+	return lvl
+}
+
+func (c *nameLevelCore) levelFor(name string) zapcore.Level {
+	lvl, ok := nameLevels.levelFor(name)
+	if !ok {
+		lvl = zapcore.LevelOf(c.level)
+	}
+
+	// the wrapped core can't write the entries below its own level
+	if inner := zapcore.LevelOf(c.Core); inner > lvl {
+		return inner
+	}
+	return lvl
+}
+
+func (c *nameLevelCore) Enabled(l zapcore.Level) bool {
+	if c.level.Enabled(l) {
+		return true
+	}
+	ruleLvl, ok := nameLevels.minLevel()
+	return ok && ruleLvl.Enabled(l)
+}
+
+func (c *nameLevelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	enabled := c.level.Enabled(ent.Level)
+	if lvl, ok := nameLevels.levelFor(ent.LoggerName); ok {
+		enabled = lvl.Enabled(ent.Level)
+	}
+
+	if enabled {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *nameLevelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &nameLevelCore{
+		c.Core.With(fields),
+		c.level,
+	}
+}
+
+// namedLeveler Core whose level depends on the name of the logger
+type namedLeveler interface {
+	levelFor(name string) zapcore.Level
+}
+
+// effectiveLevel Get the level the entries of the named logger are logged at,
+// the cores unaware of the names report their lowest enabled level
+func effectiveLevel(core zapcore.Core, name string) zapcore.Level {
+	if c, ok := core.(namedLeveler); ok {
+		return c.levelFor(name)
+	}
+	return zapcore.LevelOf(core)
+}
@@ -0,0 +1,240 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNameLevel(t *testing.T) {
+	t.Cleanup(func() { nameLevels.set(nil) })
+
+	require.NoError(t, SetNameLevels("GetApples.*.DB=debug, kafka=warn, GetApples=error, GetApples.AppleManager.DB=info"))
+
+	cases := []struct {
+		name   string
+		want   zapcore.Level
+		wantOK bool
+	}{
+		{name: "kafka", want: zapcore.WarnLevel, wantOK: true},
+		{name: "kafka.consumer", want: zapcore.WarnLevel, wantOK: true},
+		{name: "kafkaesque"},
+		{name: "http"},
+		{name: ""},
+		{name: "GetApples", want: zapcore.ErrorLevel, wantOK: true},
+		{name: "GetApples.PearManager.DB", want: zapcore.DebugLevel, wantOK: true},
+		{name: "GetApples.PearManager.DB.Tx", want: zapcore.DebugLevel, wantOK: true},
+		{name: "GetApples.PearManager.Cache", want: zapcore.ErrorLevel, wantOK: true},
+		// the exact rule is more specific than the wildcard one
+		{name: "GetApples.AppleManager.DB", want: zapcore.InfoLevel, wantOK: true},
+	}
+
+	for _, tc := range cases {
+		got, ok := NameLevel(tc.name)
+		require.Equal(t, tc.wantOK, ok, tc.name)
+		if tc.wantOK {
+			require.Equal(t, tc.want, got, tc.name)
+		}
+	}
+}
+
+func TestSetNameLevel(t *testing.T) {
+	t.Cleanup(func() { nameLevels.set(nil) })
+
+	require.NoError(t, SetNameLevel("db", zapcore.DebugLevel))
+	require.NoError(t, SetNameLevel("kafka", zapcore.WarnLevel))
+	require.Error(t, SetNameLevel("db..tx", zapcore.DebugLevel))
+	require.Error(t, SetNameLevel("db.[", zapcore.DebugLevel))
+
+	require.Equal(t, map[string]zapcore.Level{
+		"db":    zapcore.DebugLevel,
+		"kafka": zapcore.WarnLevel,
+	}, NameLevels())
+
+	UnsetNameLevel("db")
+	_, ok := NameLevel("db")
+	require.False(t, ok)
+
+	// invalid spec keeps the current rules
+	require.Error(t, SetNameLevels("db=debug,kafka=loud"))
+	require.Equal(t, map[string]zapcore.Level{"kafka": zapcore.WarnLevel}, NameLevels())
+}
+
+func TestNameLevelCoreWithName(t *testing.T) {
+	t.Cleanup(func() { nameLevels.set(nil) })
+
+	buf := bytes.Buffer{}
+	ctx := ToContext(context.Background(), NewWithSink(zapcore.ErrorLevel, &buf))
+
+	dbCtx := WithName(WithName(WithName(ctx, "GetApples"), "AppleManager"), "DB")
+	cacheCtx := WithName(WithName(WithName(ctx, "GetApples"), "AppleManager"), "Cache")
+
+	Debug(dbCtx, "hidden")
+	require.Zero(t, buf.Len())
+
+	// rules are picked up by already created loggers
+	require.NoError(t, SetNameLevel("GetApples.*.DB", zapcore.DebugLevel))
+
+	Debug(dbCtx, "db query")
+	require.Contains(t, buf.String(), "db query")
+
+	buf.Reset()
+	Info(cacheCtx, "cache miss")
+	Info(ctx, "root message")
+	require.Zero(t, buf.Len())
+
+	require.Equal(t, zapcore.DebugLevel, LevelFromContext(dbCtx))
+}
+
+func TestNewLevelBinding(t *testing.T) {
+	prev := GlobalLevel().Level()
+	t.Cleanup(func() { SetLevel(prev) })
+
+	shared := SharedLevel("TestNewLevelBinding")
+	require.Equal(t, shared, SharedLevel("TestNewLevelBinding"))
+	require.Equal(t, GlobalLevel(), SharedLevel(""))
+
+	testCases := []struct {
+		name  string
+		level zapcore.LevelEnabler
+		set   func(zapcore.Level)
+		fixed bool
+	}{
+		{name: "nil follows the global level", level: nil, set: SetLevel},
+		{name: "global level", level: GlobalLevel(), set: SetLevel},
+		{name: "shared level", level: shared, set: shared.SetLevel},
+		{name: "fixed level", level: zapcore.WarnLevel, set: SetLevel, fixed: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			ctx := ToContext(context.Background(), NewWithSink(tc.level, &buf))
+			tc.set(zapcore.WarnLevel)
+
+			Info(ctx, "hidden")
+			require.Zero(t, buf.Len())
+			require.Equal(t, zapcore.WarnLevel, NewWithSink(tc.level, io.Discard).Level())
+
+			tc.set(zapcore.DebugLevel)
+			Info(ctx, "info message")
+
+			if tc.fixed {
+				require.Zero(t, buf.Len())
+				require.Equal(t, zapcore.WarnLevel, LevelFromContext(ctx))
+				require.Equal(t, zapcore.WarnLevel, NewWithSink(tc.level, io.Discard).Level())
+				return
+			}
+			require.Contains(t, buf.String(), "info message")
+			require.Equal(t, zapcore.DebugLevel, LevelFromContext(ctx))
+			require.Equal(t, zapcore.DebugLevel, NewWithSink(tc.level, io.Discard).Level())
+		})
+	}
+}
+
+func TestNewFollowsGlobalLevel(t *testing.T) {
+	prev := GlobalLevel().Level()
+	t.Cleanup(func() { SetLevel(prev) })
+
+	for _, l := range []*zap.SugaredLogger{New(zapcore.InfoLevel), NewConsole(zapcore.InfoLevel)} {
+		SetLevel(zapcore.InfoLevel)
+		require.Equal(t, zapcore.InfoLevel, l.Level())
+
+		SetLevel(zapcore.DebugLevel)
+		require.Equal(t, zapcore.DebugLevel, l.Level())
+	}
+
+	New(zapcore.WarnLevel)
+	require.Equal(t, zapcore.WarnLevel, GlobalLevel().Level())
+}
+
+func TestWithRelativeLevel(t *testing.T) {
+	t.Cleanup(func() { nameLevels.set(nil) })
+
+	parent := SharedLevel("TestWithRelativeLevel")
+	parent.SetLevel(zapcore.InfoLevel)
+
+	buf := bytes.Buffer{}
+	base := NewWithSink(parent, &buf)
+	verbose := base.WithOptions(WithRelativeLevel(-1))
+	quiet := base.WithOptions(WithRelativeLevel(2))
+
+	verbose.Debug("verbose debug")
+	quiet.Warn("quiet warn")
+	require.Contains(t, buf.String(), "verbose debug")
+	require.NotContains(t, buf.String(), "quiet warn")
+	require.Equal(t, zapcore.DebugLevel, LevelFromContext(ToContext(context.Background(), verbose)))
+	require.Equal(t, zapcore.ErrorLevel, LevelFromContext(ToContext(context.Background(), quiet)))
+
+	// the derived loggers follow the parent level
+	parent.SetLevel(zapcore.WarnLevel)
+	buf.Reset()
+	verbose.Debug("hidden")
+	verbose.Info("verbose info")
+	require.NotContains(t, buf.String(), "hidden")
+	require.Contains(t, buf.String(), "verbose info")
+
+	// and its name override
+	require.NoError(t, SetNameLevel("db", zapcore.ErrorLevel))
+	buf.Reset()
+	verbose.Named("db").Info("hidden")
+	verbose.Named("db").Warn("db warn")
+	require.NotContains(t, buf.String(), "hidden")
+	require.Contains(t, buf.String(), "db warn")
+
+	// levels are clamped to debug..fatal
+	require.Equal(t, zapcore.DebugLevel, shiftLevel(zapcore.InfoLevel, -5))
+	require.Equal(t, zapcore.FatalLevel, shiftLevel(zapcore.InfoLevel, 10))
+}
+
+func TestEffectiveLevel(t *testing.T) {
+	prev, prevLevel := Logger(), GlobalLevel().Level()
+	t.Cleanup(func() {
+		SetLogger(prev)
+		SetLevel(prevLevel)
+		nameLevels.set(nil)
+	})
+	SetLevel(zapcore.ErrorLevel)
+
+	// the global level isn't the one of a fixed logger
+	SetLogger(NewWithSink(zapcore.DebugLevel, io.Discard))
+	require.Equal(t, zapcore.DebugLevel, EffectiveLevel(""))
+	require.Equal(t, zapcore.DebugLevel, Level())
+	require.Equal(t, zapcore.ErrorLevel, GlobalLevel().Level())
+
+	SetLogger(New(nil, WithLevel(zapcore.WarnLevel)))
+	require.Equal(t, zapcore.WarnLevel, EffectiveLevel(""))
+	require.Equal(t, zapcore.WarnLevel, Level())
+
+	SetLogger(New(nil).Named("api"))
+	require.NoError(t, SetNameLevel("api", zapcore.WarnLevel))
+	require.Equal(t, zapcore.WarnLevel, Level())
+	UnsetNameLevel("api")
+	require.Equal(t, zapcore.ErrorLevel, Level())
+
+	require.NoError(t, SetNameLevel("db", zapcore.InfoLevel))
+	require.Equal(t, zapcore.InfoLevel, EffectiveLevel("db"))
+	require.Equal(t, zapcore.ErrorLevel, EffectiveLevel("kafka"))
+}
+
+func TestNameLevelsDynamicNames(t *testing.T) {
+	t.Cleanup(func() { nameLevels.set(nil) })
+	require.NoError(t, SetNameLevels("tenant.*=debug"))
+
+	// more names than cache slots, the colliding ones are resolved again
+	for i := 0; i < 4*nameMatchCacheSize; i++ {
+		lvl, ok := NameLevel(fmt.Sprintf("tenant.%d", i))
+		require.True(t, ok)
+		require.Equal(t, zapcore.DebugLevel, lvl)
+
+		_, ok = NameLevel(fmt.Sprintf("other.%d", i))
+		require.False(t, ok)
+	}
+}
@@ -4,168 +4,231 @@ import (
 	"context"
 	"io"
 	"os"
+	"sync"
+	"sync/atomic"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
 var (
-	global       *zap.SugaredLogger
+	global       atomic.Pointer[zap.SugaredLogger]
 	defaultLevel = zap.NewAtomicLevelAt(zapcore.ErrorLevel)
+
+	subscribersMu sync.Mutex
+	subscribers   = make(map[*loggerSubscriber]struct{})
 )
 
-// init Set the default logger value
-func init() {
-	SetLogger(New(defaultLevel.Level()))
+// loggerSubscriber Wraps the callback so it can be used as a map key
+type loggerSubscriber struct {
+	fn func(prev, next *zap.SugaredLogger)
+}
+
+// New create a new logger with specific log_level & options bound to the global log_level:
+// a zapcore.Level sets the global log_level (like SetLevel) & a nil level keeps it,
+// an AtomicLevel (SharedLevel) binds the logger to it instead
+func New(level zapcore.LevelEnabler, options ...zap.Option) *zap.SugaredLogger {
+	return NewWithSink(globalBoundLevel(level), os.Stdout, options...)
 }
 
-// New create a new logger with specific log_leve & options
-func New(level zapcore.Level, options ...zap.Option) *zap.SugaredLogger {
-	return NewWithSink(level, os.Stdout, options...)
+// globalBoundLevel Get the global log_level set to the level if it's a zapcore.Level,
+// the AtomicLevels & the nil level are kept
+func globalBoundLevel(level zapcore.LevelEnabler) zapcore.LevelEnabler {
+	if lvl, ok := level.(zapcore.Level); ok {
+		SetLevel(lvl)
+		return defaultLevel
+	}
+	return level
 }
 
+// NewWithSink create a new logger writing to the sink,
+// a nil level makes the logger follow the global log_level & a zapcore.Level fixes it
 func NewWithSink(level zapcore.LevelEnabler, sink io.Writer, options ...zap.Option) *zap.SugaredLogger {
 	if level == nil {
 		level = defaultLevel
 	}
 
-	core := newZapCore(level, sink)
+	core := newZapCore(level, zapcore.NewJSONEncoder(defaultEncoderConfig()), zapcore.AddSync(sink))
 	return zap.New(core, options...).Sugar()
 }
 
-func newZapCore(level zapcore.LevelEnabler, sink io.Writer) zapcore.Core {
-	return zapcore.NewCore(
-		zapcore.NewJSONEncoder(zapcore.EncoderConfig{
-			MessageKey:     "message",
-			LevelKey:       "level",
-			TimeKey:        "ts",
-			NameKey:        "logger",
-			CallerKey:      "caller",
-			FunctionKey:    "function  ",
-			StacktraceKey:  "stacktrace",
-			LineEnding:     zapcore.DefaultLineEnding,
-			EncodeLevel:    zapcore.LowercaseLevelEncoder,
-			EncodeTime:     zapcore.ISO8601TimeEncoder,
-			EncodeDuration: zapcore.SecondsDurationEncoder,
-			EncodeCaller:   zapcore.ShortCallerEncoder,
-		}),
-		zapcore.AddSync(sink),
-		level,
-	)
-}
-
-// Level Get current log_level
+func newZapCore(level zapcore.LevelEnabler, enc zapcore.Encoder, sink zapcore.WriteSyncer) zapcore.Core {
+	return newLoggerCore(zapcore.NewCore(enc, sink, zapcore.DebugLevel), level)
+}
+
+// newLoggerCore Wrap the core writing the entries with the level decision
+// by logger name, the key deduplication & the log tags shared by all the loggers,
+// the wrapped core only restricts the level further (e.g. a tee output)
+func newLoggerCore(core zapcore.Core, level zapcore.LevelEnabler) zapcore.Core {
+	return newNameLevelCore(newDedupeCore(newTagCore(core)), level)
+}
+
+// Level Get the log_level the global logger logs at, including the override of its name,
+// GlobalLevel().Level() is the global log_level set by SetLevel
 func Level() zapcore.Level {
-	return defaultLevel.Level()
+	l := Logger().Desugar()
+	return effectiveLevel(l.Core(), l.Name())
+}
+
+// EffectiveLevel Get the log_level the entries of the global logger named name
+// (e.g. "GetApples.DB", empty for the global logger itself) are logged at,
+// including the per name overrides
+func EffectiveLevel(name string) zapcore.Level {
+	return effectiveLevel(Logger().Desugar().Core(), name)
 }
 
-// SetLevel Set current log_level
+// SetLevel Set the global log_level followed by the loggers bound to it
+// (the default one, the ones created with a nil level or GlobalLevel)
 func SetLevel(l zapcore.Level) {
 	defaultLevel.SetLevel(l)
 }
 
 // Logger Get global logger
 func Logger() *zap.SugaredLogger {
-	return global
+	return global.Load()
 }
 
-// SetLogger Set global logger (not thread safe)
+// SetLogger Set global logger, safe to call while logging concurrently.
+// The subscribers registered by OnLoggerChange are notified after the swap.
 func SetLogger(l *zap.SugaredLogger) {
-	global = l
+	prev := global.Swap(l)
+	if prev == l {
+		return
+	}
+
+	// the loggers derived from the previous one are not reachable anymore
+	spanLoggers.reset()
+	callerLoggers.reset()
+	baggageLoggers.reset()
+
+	subscribersMu.Lock()
+	fns := make([]func(prev, next *zap.SugaredLogger), 0, len(subscribers))
+	for s := range subscribers {
+		fns = append(fns, s.fn)
+	}
+	subscribersMu.Unlock()
+
+	for _, fn := range fns {
+		fn(prev, l)
+	}
+}
+
+// OnLoggerChange Subscribe to the global logger swaps, e.g. to rebuild the child
+// loggers cached by a component. The callback is called synchronously by SetLogger,
+// the returned function unsubscribes it.
+func OnLoggerChange(fn func(prev, next *zap.SugaredLogger)) (unsubscribe func()) {
+	s := &loggerSubscriber{fn: fn}
+
+	subscribersMu.Lock()
+	subscribers[s] = struct{}{}
+	subscribersMu.Unlock()
+
+	return func() {
+		subscribersMu.Lock()
+		delete(subscribers, s)
+		subscribersMu.Unlock()
+	}
+}
+
+// callerLogger Get the logger used by the package functions, it skips their
+// frame and the ones added by WithCallerSkip so the caller is the user code.
+// Every package level log function must call the logger it returns directly.
+func callerLogger(ctx context.Context, l *zap.SugaredLogger) *zap.SugaredLogger {
+	return loggerWithCachedCallerSkip(l, 1+callerSkipFromContext(ctx))
 }
 
 func Debug(ctx context.Context, args ...interface{}) {
-	if l := FromContext(ctx); l.Level().Enabled(zapcore.DebugLevel) {
-		l.Debug(args...)
+	if l := loggerFromContext(ctx); l.Level().Enabled(zapcore.DebugLevel) {
+		callerLogger(ctx, loggerWithKvs(ctx, l)).Debug(args...)
 	}
 }
 
 func Debugf(ctx context.Context, format string, args ...interface{}) {
-	if l := FromContext(ctx); l.Level().Enabled(zapcore.DebugLevel) {
-		l.Debugf(format, args...)
+	if l := loggerFromContext(ctx); l.Level().Enabled(zapcore.DebugLevel) {
+		callerLogger(ctx, loggerWithKvs(ctx, l)).Debugf(format, args...)
 	}
 }
 
 func DebugKV(ctx context.Context, message string, kvs ...interface{}) {
-	if l := FromContext(ctx); l.Level().Enabled(zapcore.DebugLevel) {
-		l.Debugw(message, mergeKvs(ctx, kvs...)...)
+	if l := loggerFromContext(ctx); l.Level().Enabled(zapcore.DebugLevel) {
+		callerLogger(ctx, l).Debugw(message, mergeKvs(ctx, kvs...)...)
 	}
 }
 
 func Info(ctx context.Context, args ...interface{}) {
-	if l := FromContext(ctx); l.Level().Enabled(zapcore.InfoLevel) {
-		l.Info(args...)
+	if l := loggerFromContext(ctx); l.Level().Enabled(zapcore.InfoLevel) {
+		callerLogger(ctx, loggerWithKvs(ctx, l)).Info(args...)
 	}
 }
 
 func Infof(ctx context.Context, format string, args ...interface{}) {
-	if l := FromContext(ctx); l.Level().Enabled(zapcore.InfoLevel) {
-		l.Infof(format, args...)
+	if l := loggerFromContext(ctx); l.Level().Enabled(zapcore.InfoLevel) {
+		callerLogger(ctx, loggerWithKvs(ctx, l)).Infof(format, args...)
 	}
 }
 
 func InfoKV(ctx context.Context, message string, kvs ...interface{}) {
-	if l := FromContext(ctx); l.Level().Enabled(zapcore.InfoLevel) {
-		l.Infow(message, mergeKvs(ctx, kvs...)...)
+	if l := loggerFromContext(ctx); l.Level().Enabled(zapcore.InfoLevel) {
+		callerLogger(ctx, l).Infow(message, mergeKvs(ctx, kvs...)...)
 	}
 }
 
 func Warn(ctx context.Context, args ...interface{}) {
-	if l := FromContext(ctx); l.Level().Enabled(zapcore.WarnLevel) {
-		l.Warn(args...)
+	if l := loggerFromContext(ctx); l.Level().Enabled(zapcore.WarnLevel) {
+		callerLogger(ctx, loggerWithKvs(ctx, l)).Warn(args...)
 	}
 }
 
 func Warnf(ctx context.Context, format string, args ...interface{}) {
-	if l := FromContext(ctx); l.Level().Enabled(zapcore.WarnLevel) {
-		l.Warnf(format, args...)
+	if l := loggerFromContext(ctx); l.Level().Enabled(zapcore.WarnLevel) {
+		callerLogger(ctx, loggerWithKvs(ctx, l)).Warnf(format, args...)
 	}
 }
 
 func WarnKV(ctx context.Context, message string, kvs ...interface{}) {
-	if l := FromContext(ctx); l.Level().Enabled(zapcore.WarnLevel) {
-		l.Warnw(message, mergeKvs(ctx, kvs...)...)
+	if l := loggerFromContext(ctx); l.Level().Enabled(zapcore.WarnLevel) {
+		callerLogger(ctx, l).Warnw(message, mergeKvs(ctx, kvs...)...)
 	}
 }
 
 func Error(ctx context.Context, args ...interface{}) {
-	if l := FromContext(ctx); l.Level().Enabled(zapcore.ErrorLevel) {
-		l.Error(args...)
+	if l := loggerFromContext(ctx); l.Level().Enabled(zapcore.ErrorLevel) {
+		callerLogger(ctx, loggerWithKvs(ctx, l)).Error(args...)
 	}
 }
 
 func Errorf(ctx context.Context, format string, args ...interface{}) {
-	if l := FromContext(ctx); l.Level().Enabled(zapcore.ErrorLevel) {
-		l.Errorf(format, args...)
+	if l := loggerFromContext(ctx); l.Level().Enabled(zapcore.ErrorLevel) {
+		callerLogger(ctx, loggerWithKvs(ctx, l)).Errorf(format, args...)
 	}
 }
 
 func ErrorKV(ctx context.Context, message string, kvs ...interface{}) {
-	if l := FromContext(ctx); l.Level().Enabled(zapcore.ErrorLevel) {
-		l.Errorw(message, mergeKvs(ctx, kvs...)...)
+	if l := loggerFromContext(ctx); l.Level().Enabled(zapcore.ErrorLevel) {
+		callerLogger(ctx, l).Errorw(message, mergeKvs(ctx, kvs...)...)
 	}
 }
 
 func Fatal(ctx context.Context, args ...interface{}) {
-	FromContext(ctx).Fatal(args...)
+	callerLogger(ctx, FromContext(ctx)).Fatal(args...)
 }
 
 func Fatalf(ctx context.Context, format string, args ...interface{}) {
-	FromContext(ctx).Fatalf(format, args)
+	callerLogger(ctx, FromContext(ctx)).Fatalf(format, args...)
 }
 
 func FatalKV(ctx context.Context, message string, kvs ...interface{}) {
-	FromContext(ctx).Fatalw(message, mergeKvs(ctx, kvs...)...)
+	callerLogger(ctx, loggerFromContext(ctx)).Fatalw(message, mergeKvs(ctx, kvs...)...)
 }
 
 func Panic(ctx context.Context, args ...interface{}) {
-	FromContext(ctx).Panic(args...)
+	callerLogger(ctx, FromContext(ctx)).Panic(args...)
 }
 
 func Panicf(ctx context.Context, format string, args ...interface{}) {
-	FromContext(ctx).Panicf(format, args...)
+	callerLogger(ctx, FromContext(ctx)).Panicf(format, args...)
 }
 
 func PanicKV(ctx context.Context, message string, kvs ...interface{}) {
-	FromContext(ctx).Panicw(message, mergeKvs(ctx, kvs...)...)
+	callerLogger(ctx, loggerFromContext(ctx)).Panicw(message, mergeKvs(ctx, kvs...)...)
 }
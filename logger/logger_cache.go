@@ -0,0 +1,200 @@
+package logger
+
+import (
+	"encoding/binary"
+	"hash/maphash"
+	"sync/atomic"
+	"unsafe"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// loggerCacheSize Number of slots of the derived loggers caches
+const loggerCacheSize = 1024
+
+var (
+	// spanLoggers Span enriched loggers reused by FromContext, the loggers with an
+	// OpenTelemetry core hold their span so up to loggerCacheSize spans stay referenced
+	spanLoggers = loggerCache[spanLoggerKey]{hash: spanLoggerKey.hash}
+	// callerLoggers Loggers skipping the package functions frames
+	callerLoggers = loggerCache[callerLoggerKey]{hash: callerLoggerKey.hash}
+	// baggageLoggers Loggers enriched with the baggage members & the AddKV fields
+	baggageLoggers baggageLoggerCache
+)
+
+// loggerCache Direct mapped cache of the loggers derived from another one,
+// a slot holds the last logger computed for it and is overwritten on collision
+// so the cache is bounded and lookups never lock nor allocate
+type loggerCache[K comparable] struct {
+	slots [loggerCacheSize]atomic.Pointer[loggerCacheEntry[K]]
+	hash  func(K) uint64
+}
+
+type loggerCacheEntry[K comparable] struct {
+	key    K
+	logger *zap.SugaredLogger
+}
+
+func (c *loggerCache[K]) get(key K) (*zap.SugaredLogger, bool) {
+	if entry := c.slots[c.hash(key)%loggerCacheSize].Load(); entry != nil && entry.key == key {
+		return entry.logger, true
+	}
+	return nil, false
+}
+
+func (c *loggerCache[K]) put(key K, l *zap.SugaredLogger) {
+	c.slots[c.hash(key)%loggerCacheSize].Store(&loggerCacheEntry[K]{key: key, logger: l})
+}
+
+// reset Drop all the cached loggers
+func (c *loggerCache[K]) reset() {
+	for i := range c.slots {
+		c.slots[i].Store(nil)
+	}
+}
+
+type spanLoggerKey struct {
+	logger  *zap.SugaredLogger
+	traceID trace.TraceID
+	spanID  trace.SpanID
+	flags   trace.TraceFlags
+	remote  bool
+	// traceState the encoded W3C trace state, empty for most spans
+	traceState string
+	recording  bool
+	// generation of the trace formatter the logger is enriched by
+	generation uint64
+}
+
+// loggerWithCachedSpan Get the logger enriched with the span, computing it once per span
+func loggerWithCachedSpan(l *zap.SugaredLogger, span trace.Span) *zap.SugaredLogger {
+	traceFormatter := currentTraceFormatter.Load()
+	spanCtx := span.SpanContext()
+	key := spanLoggerKey{
+		logger:     l,
+		traceID:    spanCtx.TraceID(),
+		spanID:     spanCtx.SpanID(),
+		flags:      spanCtx.TraceFlags(),
+		remote:     spanCtx.IsRemote(),
+		recording:  span.IsRecording(),
+		generation: traceFormatter.generation,
+	}
+
+	if spanCtx.TraceState().Len() > 0 {
+		key.traceState = spanCtx.TraceState().String()
+	}
+
+	if cached, ok := spanLoggers.get(key); ok {
+		return cached
+	}
+
+	enriched := loggerWithSpan(l, span, traceFormatter.formatter)
+	spanLoggers.put(key, enriched)
+	return enriched
+}
+
+func (k spanLoggerKey) hash() uint64 {
+	// span ids are random, mixing in the logger address
+	// spreads the loggers sharing a span across the slots
+	return mixHash(binary.BigEndian.Uint64(k.spanID[:]) ^ pointerHash(k.logger))
+}
+
+// baggageLoggerCache Direct mapped cache of the loggers enriched with baggage members,
+// like loggerCache but the members aren't comparable so they're checked on lookup
+type baggageLoggerCache struct {
+	slots [loggerCacheSize]atomic.Pointer[baggageLoggerEntry]
+}
+
+type baggageLoggerEntry struct {
+	logger  *zap.SugaredLogger
+	node    *kvNode
+	config  *BaggageFields
+	members []baggageMember
+	// enriched the logger with the members & the AddKV fields attached
+	enriched *zap.SugaredLogger
+}
+
+var baggageHashSeed = maphash.MakeSeed()
+
+// loggerWithCachedBaggage Get the logger with the baggage members & the AddKV fields attached,
+// computing it once per logger, AddKV node, baggage fields config & member values
+func loggerWithCachedBaggage(l *zap.SugaredLogger, node *kvNode, config *BaggageFields, members []baggageMember) *zap.SugaredLogger {
+	var h maphash.Hash
+	h.SetSeed(baggageHashSeed)
+	for _, m := range members {
+		h.WriteString(m.key)
+		h.WriteByte(0)
+		h.WriteString(m.value)
+		h.WriteByte(0)
+	}
+	slot := &baggageLoggers.slots[mixHash(h.Sum64()^pointerHash(l)^uint64(uintptr(unsafe.Pointer(node))))%loggerCacheSize]
+
+	if entry := slot.Load(); entry != nil && entry.logger == l && entry.node == node &&
+		entry.config == config && equalMembers(entry.members, members) {
+		return entry.enriched
+	}
+
+	enriched := l.With(mergeFields(config.kvs(members), node.resolve())...)
+	slot.Store(&baggageLoggerEntry{
+		logger:   l,
+		node:     node,
+		config:   config,
+		members:  append([]baggageMember(nil), members...),
+		enriched: enriched,
+	})
+	return enriched
+}
+
+// reset Drop all the cached loggers
+func (c *baggageLoggerCache) reset() {
+	for i := range c.slots {
+		c.slots[i].Store(nil)
+	}
+}
+
+func equalMembers(a, b []baggageMember) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type callerLoggerKey struct {
+	logger *zap.SugaredLogger
+	skip   int
+}
+
+// loggerWithCachedCallerSkip Get the logger skipping additional caller frames
+func loggerWithCachedCallerSkip(l *zap.SugaredLogger, skip int) *zap.SugaredLogger {
+	key := callerLoggerKey{logger: l, skip: skip}
+
+	if cached, ok := callerLoggers.get(key); ok {
+		return cached
+	}
+
+	skipping := l.WithOptions(zap.AddCallerSkip(skip))
+	callerLoggers.put(key, skipping)
+	return skipping
+}
+
+func (k callerLoggerKey) hash() uint64 {
+	return mixHash(pointerHash(k.logger) + uint64(k.skip))
+}
+
+func pointerHash(l *zap.SugaredLogger) uint64 {
+	return uint64(uintptr(unsafe.Pointer(l)))
+}
+
+// mixHash Finalizer of murmur3 spreading the bits over the slots
+func mixHash(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	return h
+}
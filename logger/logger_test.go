@@ -3,13 +3,16 @@ package logger
 import (
 	"context"
 	"fmt"
+	"io"
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest/observer"
@@ -414,6 +417,154 @@ func TestSimple(t *testing.T) {
 	}
 }
 
+func TestCaller(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		fn   func(ctx context.Context)
+	}{
+		{name: "Debug", fn: func(ctx context.Context) { Debug(ctx, "message") }},
+		{name: "Debugf", fn: func(ctx context.Context) { Debugf(ctx, "message %d", 1) }},
+		{name: "DebugKV", fn: func(ctx context.Context) { DebugKV(ctx, "message", "key", "value") }},
+		{name: "Info", fn: func(ctx context.Context) { Info(ctx, "message") }},
+		{name: "Infof", fn: func(ctx context.Context) { Infof(ctx, "message %d", 1) }},
+		{name: "InfoKV", fn: func(ctx context.Context) { InfoKV(ctx, "message", "key", "value") }},
+		{name: "Warn", fn: func(ctx context.Context) { Warn(ctx, "message") }},
+		{name: "Warnf", fn: func(ctx context.Context) { Warnf(ctx, "message %d", 1) }},
+		{name: "WarnKV", fn: func(ctx context.Context) { WarnKV(ctx, "message", "key", "value") }},
+		{name: "Error", fn: func(ctx context.Context) { Error(ctx, "message") }},
+		{name: "Errorf", fn: func(ctx context.Context) { Errorf(ctx, "message %d", 1) }},
+		{name: "ErrorKV", fn: func(ctx context.Context) { ErrorKV(ctx, "message", "key", "value") }},
+		{name: "Panic", fn: func(ctx context.Context) { require.Panics(t, func() { Panic(ctx, "message") }) }},
+		{name: "Panicf", fn: func(ctx context.Context) { require.Panics(t, func() { Panicf(ctx, "message %d", 1) }) }},
+		{name: "PanicKV", fn: func(ctx context.Context) { require.Panics(t, func() { PanicKV(ctx, "message", "key", "value") }) }},
+		{name: "FromContext", fn: func(ctx context.Context) { FromContext(ctx).Info("message") }},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			core, logs := observer.New(zapcore.DebugLevel)
+			ctx := ToContext(context.Background(), zap.New(core, zap.AddCaller()).Sugar())
+			ctx = AddKV(ctx, "request_id", "abc")
+
+			// act
+			tc.fn(ctx)
+
+			// assert
+			records := logs.All()
+			require.Len(t, records, 1)
+			require.True(t, records[0].Caller.Defined)
+			require.True(t, strings.HasPrefix(records[0].Caller.TrimmedPath(), "logger/logger_test.go:"), records[0].Caller.TrimmedPath())
+			require.Contains(t, records[0].Caller.Function, "TestCaller")
+		})
+	}
+}
+
+func TestWithCallerSkip(t *testing.T) {
+	t.Parallel()
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	ctx := ToContext(context.Background(), zap.New(core, zap.AddCaller()).Sugar())
+
+	_, _, line, _ := runtime.Caller(0)
+	testLogWrapper(ctx, "message")
+	testLogWrapperKV(ctx, "message")
+
+	records := logs.All()
+	require.Len(t, records, 2)
+	for i, record := range records {
+		require.Equal(t, line+1+i, record.Caller.Line)
+		require.Contains(t, record.Caller.Function, "TestWithCallerSkip")
+	}
+}
+
+func testLogWrapper(ctx context.Context, message string) {
+	Info(WithCallerSkip(ctx, 1), message)
+}
+
+func testLogWrapperKV(ctx context.Context, message string) {
+	// nested wrappers add their frames up
+	testLogWrapperKVInner(WithCallerSkip(ctx, 1), message)
+}
+
+func testLogWrapperKVInner(ctx context.Context, message string) {
+	InfoKV(WithCallerSkip(ctx, 1), message, "key", "value")
+}
+
+func TestOnLoggerChange(t *testing.T) {
+	prev := Logger()
+	t.Cleanup(func() { SetLogger(prev) })
+
+	var calls [][2]*zap.SugaredLogger
+	unsubscribe := OnLoggerChange(func(prev, next *zap.SugaredLogger) {
+		calls = append(calls, [2]*zap.SugaredLogger{prev, next})
+	})
+
+	first := NewWithSink(nil, io.Discard)
+	second := NewWithSink(nil, io.Discard)
+
+	SetLogger(first)
+	SetLogger(first) // no swap, no notification
+	SetLogger(second)
+	unsubscribe()
+	SetLogger(first)
+
+	require.Equal(t, [][2]*zap.SugaredLogger{
+		{prev, first},
+		{first, second},
+	}, calls)
+	require.Same(t, first, Logger())
+}
+
+func TestSetLoggerConcurrent(t *testing.T) {
+	prev := Logger()
+	t.Cleanup(func() { SetLogger(prev) })
+
+	loggers := []*zap.SugaredLogger{
+		NewWithSink(zapcore.DebugLevel, io.Discard),
+		NewWithSink(zapcore.DebugLevel, io.Discard),
+	}
+
+	ctx := AddKV(context.Background(), "request_id", "abc")
+	ctx = trace.ContextWithSpanContext(ctx, testSpanContext(t, "a48b167265f65931"))
+
+	var (
+		wg   sync.WaitGroup
+		stop = make(chan struct{})
+	)
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				Info(ctx, "message")
+				InfoKV(WithName(ctx, "worker"), "message", "key", "value")
+				assert.NotNil(t, FromContext(ctx))
+				assert.NotNil(t, Logger())
+			}
+		}()
+	}
+
+	for i := 0; i < 1000; i++ {
+		SetLogger(loggers[i%len(loggers)])
+	}
+	close(stop)
+	wg.Wait()
+}
+
 func formatTestKVCase(fn interface{}, level zapcore.Level) string {
 	return fmt.Sprintf("fn:%s_loglevel:%s", getFunctionName(fn), level.String())
 }
@@ -0,0 +1,310 @@
+// Package logtest Observe the entries written through the logger package in tests
+// and assert on them:
+//
+//	ctx, logs := logtest.ToContext(context.Background())
+//	handle(ctx)
+//	logs.AssertLogged(t, zapcore.ErrorLevel, "payment failed", "user_id", 5)
+//	logs.AssertNoEntriesAt(t, zapcore.DPanicLevel)
+package logtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/catalystgo/logger/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// EnvUpdateGolden Environment variable making AssertGolden write the golden files
+// instead of comparing them, e.g. LOG_UPDATE_GOLDEN=1 go test ./...
+const EnvUpdateGolden = "LOG_UPDATE_GOLDEN"
+
+// normalizedTime Value replacing the timestamps in the golden files
+const normalizedTime = `"ts":"<ts>"`
+
+// tsPattern Timestamp of an entry, the first key written after the level
+var tsPattern = regexp.MustCompile(`"ts":"[^"]*"`)
+
+// Entry Entry written by an observed logger
+type Entry struct {
+	Level   zapcore.Level
+	Time    time.Time
+	Logger  string
+	Message string
+	Caller  string
+	Stack   string
+	// Fields the other keys, the numbers are decoded as json.Number
+	Fields map[string]interface{}
+}
+
+// Logs Entries written by an observed logger, safe for concurrent use
+type Logs struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	logger *zap.SugaredLogger
+}
+
+// New create an observed logger writing all the entries as JSON,
+// through the same encoding, level & field handling as logger.NewWithSink
+func New(options ...zap.Option) *Logs {
+	l := &Logs{}
+	l.logger = logger.NewWithSink(zapcore.DebugLevel, l, options...)
+	return l
+}
+
+// ToContext Attach a new observed logger to the context
+func ToContext(ctx context.Context, options ...zap.Option) (context.Context, *Logs) {
+	l := New(options...)
+	return logger.ToContext(ctx, l.logger), l
+}
+
+// SetGlobal Set a new observed logger as the global logger, the previous one
+// is restored when the test ends. The tests calling it can't run in parallel.
+func SetGlobal(t testing.TB, options ...zap.Option) *Logs {
+	t.Helper()
+
+	l := New(options...)
+	prev := logger.Logger()
+	logger.SetLogger(l.logger)
+	t.Cleanup(func() { logger.SetLogger(prev) })
+	return l
+}
+
+// Logger Get the observed logger
+func (l *Logs) Logger() *zap.SugaredLogger {
+	return l.logger
+}
+
+// Write Record the entries written by the observed logger
+func (l *Logs) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.buf.Write(p)
+}
+
+// Reset Forget the entries written so far
+func (l *Logs) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.buf.Reset()
+}
+
+// lines Get the JSON lines written so far
+func (l *Logs) lines() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var lines []string
+	for _, line := range strings.Split(l.buf.String(), "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Entries Get the entries written so far
+func (l *Logs) Entries() []Entry {
+	lines := l.lines()
+	entries := make([]Entry, 0, len(lines))
+	for _, line := range lines {
+		entries = append(entries, parseEntry(line))
+	}
+	return entries
+}
+
+// AssertLogged Fail the test if no entry has the level, the message & the fields.
+// The field values are compared by their JSON encoding, e.g. 5 matches "user_id": 5.
+func (l *Logs) AssertLogged(t testing.TB, level zapcore.Level, message string, kvs ...interface{}) {
+	t.Helper()
+
+	match := newMatcher(t, level, message, kvs)
+	entries := l.Entries()
+	for _, e := range entries {
+		if match(e) {
+			return
+		}
+	}
+	t.Errorf("logtest: no %s entry %q with %v was logged, got:\n%s", level, message, kvs, formatEntries(entries))
+}
+
+// AssertNotLogged Fail the test if an entry has the level, the message & the fields
+func (l *Logs) AssertNotLogged(t testing.TB, level zapcore.Level, message string, kvs ...interface{}) {
+	t.Helper()
+
+	match := newMatcher(t, level, message, kvs)
+	for _, e := range l.Entries() {
+		if match(e) {
+			t.Errorf("logtest: unexpected %s entry %q with %v was logged:\n%s", level, message, kvs, formatEntries([]Entry{e}))
+			return
+		}
+	}
+}
+
+// AssertNoEntriesAt Fail the test if an entry at or above the level was logged
+func (l *Logs) AssertNoEntriesAt(t testing.TB, level zapcore.Level) {
+	t.Helper()
+
+	var found []Entry
+	for _, e := range l.Entries() {
+		if e.Level >= level {
+			found = append(found, e)
+		}
+	}
+	if len(found) > 0 {
+		t.Errorf("logtest: %d entries at or above %s were logged:\n%s", len(found), level, formatEntries(found))
+	}
+}
+
+// AssertGolden Fail the test if the entries differ from the golden file,
+// the timestamps are normalized. The file is written instead when EnvUpdateGolden is set.
+func (l *Logs) AssertGolden(t testing.TB, path string) {
+	t.Helper()
+
+	lines := l.lines()
+	for i, line := range lines {
+		if loc := tsPattern.FindStringIndex(line); loc != nil {
+			lines[i] = line[:loc[0]] + normalizedTime + line[loc[1]:]
+		}
+	}
+	got := strings.Join(lines, "\n") + "\n"
+
+	if os.Getenv(EnvUpdateGolden) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("logtest: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("logtest: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("logtest: %v (set %s=1 to create the golden file)", err, EnvUpdateGolden)
+	}
+	if got == string(want) {
+		return
+	}
+
+	wantLines := strings.Split(strings.TrimSuffix(string(want), "\n"), "\n")
+	gotLines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var wantLine, gotLine string
+		if i < len(wantLines) {
+			wantLine = wantLines[i]
+		}
+		if i < len(gotLines) {
+			gotLine = gotLines[i]
+		}
+		if wantLine != gotLine {
+			t.Errorf("logtest: entries differ from %s at line %d:\nwant: %s\ngot:  %s", path, i+1, wantLine, gotLine)
+			return
+		}
+	}
+}
+
+// newMatcher Get a check of the entries having the level, the message & the fields
+func newMatcher(t testing.TB, level zapcore.Level, message string, kvs []interface{}) func(Entry) bool {
+	t.Helper()
+
+	if len(kvs)%2 != 0 {
+		t.Fatalf("logtest: odd number of kvs %v", kvs)
+	}
+
+	expected := make(map[string]string, len(kvs)/2)
+	for i := 0; i < len(kvs); i += 2 {
+		key, ok := kvs[i].(string)
+		if !ok {
+			t.Fatalf("logtest: key %v isn't a string", kvs[i])
+		}
+		expected[key] = encodeValue(kvs[i+1])
+	}
+
+	return func(e Entry) bool {
+		if e.Level != level || e.Message != message {
+			return false
+		}
+		for key, value := range expected {
+			actual, ok := e.Fields[key]
+			if !ok || encodeValue(actual) != value {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// encodeValue Get the JSON encoding the values are compared by
+func encodeValue(v interface{}) string {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(raw)
+}
+
+// parseEntry Decode the JSON line written by the observed logger
+func parseEntry(line string) Entry {
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+
+	var fields map[string]interface{}
+	if err := dec.Decode(&fields); err != nil {
+		return Entry{Message: line, Fields: map[string]interface{}{}}
+	}
+
+	e := Entry{Fields: fields}
+	if lvl, ok := popString(fields, "level"); ok {
+		_ = e.Level.UnmarshalText([]byte(lvl))
+	}
+	if ts, ok := popString(fields, "ts"); ok {
+		e.Time, _ = time.Parse("2006-01-02T15:04:05.000Z0700", ts)
+	}
+	e.Logger, _ = popString(fields, "logger")
+	e.Message, _ = popString(fields, "message")
+	e.Caller, _ = popString(fields, "caller")
+	e.Stack, _ = popString(fields, "stacktrace")
+	return e
+}
+
+func popString(fields map[string]interface{}, key string) (string, bool) {
+	v, ok := fields[key].(string)
+	if ok {
+		delete(fields, key)
+	}
+	return v, ok
+}
+
+func formatEntries(entries []Entry) string {
+	if len(entries) == 0 {
+		return "\t(no entries)"
+	}
+
+	var b strings.Builder
+	for i, e := range entries {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "\t%s %q", e.Level, e.Message)
+		if e.Logger != "" {
+			fmt.Fprintf(&b, " logger=%s", e.Logger)
+		}
+		if len(e.Fields) > 0 {
+			fmt.Fprintf(&b, " %s", encodeValue(e.Fields))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,146 @@
+package logtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/catalystgo/logger/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// recordingTB Records the failures instead of failing the test
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestToContext(t *testing.T) {
+	t.Parallel()
+
+	ctx, logs := ToContext(context.Background())
+	ctx = logger.AddKV(logger.WithName(ctx, "payments"), "user_id", 5)
+
+	logger.Debug(ctx, "charging")
+	logger.ErrorKV(ctx, "payment failed", "amount", 9.99, "error", errors.New("card declined"))
+
+	entries := logs.Entries()
+	require.Len(t, entries, 2)
+	require.Equal(t, zapcore.DebugLevel, entries[0].Level)
+	require.Equal(t, "payments", entries[1].Logger)
+	require.False(t, entries[1].Time.IsZero())
+
+	logs.AssertLogged(t, zapcore.ErrorLevel, "payment failed", "user_id", 5, "amount", 9.99)
+	logs.AssertNotLogged(t, zapcore.ErrorLevel, "payment failed", "user_id", 6)
+	logs.AssertNoEntriesAt(t, zapcore.DPanicLevel)
+
+	testCases := []struct {
+		name     string
+		assert   func(t testing.TB)
+		expected string
+	}{
+		{
+			name: "other field value",
+			assert: func(t testing.TB) {
+				logs.AssertLogged(t, zapcore.ErrorLevel, "payment failed", "user_id", "5")
+			},
+			expected: `no error entry "payment failed" with [user_id 5] was logged`,
+		},
+		{
+			name: "other level",
+			assert: func(t testing.TB) {
+				logs.AssertLogged(t, zapcore.WarnLevel, "payment failed")
+			},
+			expected: `error "payment failed" logger=payments {"amount":9.99,"error":"card declined","user_id":5}`,
+		},
+		{
+			name: "logged",
+			assert: func(t testing.TB) {
+				logs.AssertNotLogged(t, zapcore.DebugLevel, "charging")
+			},
+			expected: `unexpected debug entry "charging" with [] was logged`,
+		},
+		{
+			name: "error entries",
+			assert: func(t testing.TB) {
+				logs.AssertNoEntriesAt(t, zapcore.WarnLevel)
+			},
+			expected: "1 entries at or above warn were logged",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := &recordingTB{TB: t}
+			tc.assert(r)
+			require.Len(t, r.errors, 1)
+			require.Contains(t, r.errors[0], tc.expected)
+		})
+	}
+}
+
+func TestReset(t *testing.T) {
+	t.Parallel()
+
+	ctx, logs := ToContext(context.Background())
+	logger.Error(ctx, "failed")
+	logs.Reset()
+
+	require.Empty(t, logs.Entries())
+	logs.AssertNoEntriesAt(t, zapcore.DebugLevel)
+}
+
+func TestSetGlobal(t *testing.T) {
+	prev := logger.Logger()
+
+	t.Run("observed", func(t *testing.T) {
+		logs := SetGlobal(t)
+		logger.WarnKV(context.Background(), "disk almost full", "free", "5%")
+		logs.AssertLogged(t, zapcore.WarnLevel, "disk almost full", "free", "5%")
+	})
+
+	require.Same(t, prev, logger.Logger())
+}
+
+func TestAssertGolden(t *testing.T) {
+	t.Parallel()
+
+	ctx, logs := ToContext(context.Background())
+	logger.InfoKV(logger.WithName(ctx, "orders"), "order created", "order_id", 42, "items", []string{"apple"})
+	logger.Warn(ctx, "stock low")
+
+	logs.AssertGolden(t, "testdata/golden.jsonl")
+
+	logger.Info(ctx, "unexpected")
+	r := &recordingTB{TB: t}
+	logs.AssertGolden(r, "testdata/golden.jsonl")
+	require.Len(t, r.errors, 1)
+	require.Contains(t, r.errors[0], "at line 3")
+}
+
+func TestAssertGoldenUpdate(t *testing.T) {
+	t.Setenv(EnvUpdateGolden, "1")
+
+	ctx, logs := ToContext(context.Background())
+	logger.Info(ctx, "hello")
+
+	path := filepath.Join(t.TempDir(), "testdata", "hello.jsonl")
+	logs.AssertGolden(t, path)
+
+	golden, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, `{"level":"info","ts":"<ts>","message":"hello"}`+"\n", string(golden))
+}
@@ -0,0 +1,537 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxTagDepth Nesting level the tagged values stop being encoded at, to survive cycles
+const maxTagDepth = 32
+
+var (
+	// structInfos Encoded fields by struct type
+	structInfos sync.Map
+	// taggedTypes Whether the type or one of the types it holds has log tags
+	taggedTypes sync.Map
+)
+
+// Object Field encoding the value by its `log` struct tags, see MarshalObject
+func Object(key string, v any) zap.Field {
+	return zap.Object(key, MarshalObject(v))
+}
+
+// MarshalObject Get an ObjectMarshaler encoding the struct (or map) by the `log` tags
+// of its fields, the nested values are encoded the same way:
+//
+//	type Order struct {
+//		ID     int64  `log:"id"`
+//		Coupon string `log:"coupon,omitempty"`
+//		Card   string `log:"card,redact"`
+//		Email  string `log:"email,hash"`
+//		Secret string `log:"-"`
+//	}
+//
+// A field without a `log` tag is named after its `json` tag or its name.
+// The structs holding `log` tags are encoded that way even when logged with zap.Any
+// or as a kv (e.g. InfoKV(ctx, "created", "order", order)).
+func MarshalObject(v any) zapcore.ObjectMarshaler {
+	return taggedObject{reflect.ValueOf(v), 0}
+}
+
+// AddTagged Add the value to the object the way MarshalObject encodes a struct field,
+// used by the code generated by cmd/logmarshal for the values it can't encode statically
+func AddTagged(enc zapcore.ObjectEncoder, key string, v any) error {
+	return encodeValue(objectValueEncoder{enc, key}, reflect.ValueOf(v), 0)
+}
+
+// AppendTagged Append the value to the array the way MarshalObject encodes a slice element,
+// used by the code generated by cmd/logmarshal for the values it can't encode statically
+func AppendTagged(enc zapcore.ArrayEncoder, v any) error {
+	return encodeValue(arrayValueEncoder{enc}, reflect.ValueOf(v), 0)
+}
+
+// IsEmpty Whether a struct field holding the value is omitted by omitempty,
+// used by the code generated by cmd/logmarshal for the values it can't check statically
+func IsEmpty(v any) bool {
+	rv := reflect.ValueOf(v)
+	return !rv.IsValid() || isEmptyValue(rv)
+}
+
+// Hash Get the value written for a struct field tagged with hash,
+// keyed by SetHashKey (plain SHA-256 without a key, see SetHashKey)
+func Hash(v any) string {
+	if s, ok := v.(string); ok {
+		return hashValue(currentHashKey(), s)
+	}
+	return hashValue(currentHashKey(), valueString(reflect.ValueOf(v)))
+}
+
+// structInfo Encoded fields of a struct type
+type structInfo struct {
+	fields []structField
+}
+
+type structField struct {
+	index     []int
+	name      string
+	omitEmpty bool
+	redact    bool
+	hash      bool
+}
+
+func cachedStructInfo(t reflect.Type) *structInfo {
+	if info, ok := structInfos.Load(t); ok {
+		return info.(*structInfo)
+	}
+
+	info := &structInfo{fields: appendStructFields(nil, t, nil)}
+	actual, _ := structInfos.LoadOrStore(t, info)
+	return actual.(*structInfo)
+}
+
+func appendStructFields(fields []structField, t reflect.Type, index []int) []structField {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		logTag, hasLogTag := sf.Tag.Lookup("log")
+		if logTag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(logTag, ",")
+
+		if !hasLogTag {
+			jsonTag := sf.Tag.Get("json")
+			if jsonTag == "-" {
+				continue
+			}
+			name, _, _ = strings.Cut(jsonTag, ",")
+		}
+
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		// embedded structs without a name are inlined
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fields = appendStructFields(fields, ft, fieldIndex)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		field := structField{index: fieldIndex, name: name}
+		if field.name == "" {
+			field.name = sf.Name
+		}
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "omitempty":
+				field.omitEmpty = true
+			case "redact":
+				field.redact = true
+			case "hash":
+				field.hash = true
+			}
+		}
+
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// hasLogTags Check whether the type or one of the types it holds has log tags
+func hasLogTags(t reflect.Type) bool {
+	if tagged, ok := taggedTypes.Load(t); ok {
+		return tagged.(bool)
+	}
+
+	tagged := findLogTags(t, make(map[reflect.Type]struct{}))
+	taggedTypes.Store(t, tagged)
+	return tagged
+}
+
+func findLogTags(t reflect.Type, visited map[reflect.Type]struct{}) bool {
+	if _, ok := visited[t]; ok {
+		return false
+	}
+	visited[t] = struct{}{}
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return findLogTags(t.Elem(), visited)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if _, ok := sf.Tag.Lookup("log"); ok {
+				return true
+			}
+			if findLogTags(sf.Type, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// taggedField Get a field encoding the value by its log tags,
+// false if the value doesn't have any
+func taggedField(key string, v any) (zap.Field, bool) {
+	if v == nil {
+		return zap.Field{}, false
+	}
+
+	// the values knowing how to encode themselves are left as is
+	switch v.(type) {
+	case zapcore.ObjectMarshaler, zapcore.ArrayMarshaler, json.Marshaler, fmt.Stringer, error:
+		return zap.Field{}, false
+	}
+
+	t := reflect.TypeOf(v)
+	if !hasLogTags(t) {
+		return zap.Field{}, false
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return zap.Object(key, taggedObject{reflect.ValueOf(v), 0}), true
+	case reflect.Slice, reflect.Array:
+		return zap.Array(key, taggedArray{reflect.ValueOf(v), 0}), true
+	}
+	return zap.Field{}, false
+}
+
+// taggedFields Get the fields with the tagged values encoded by their tags,
+// the slice is copied only if a field changes
+func taggedFields(fields []zapcore.Field) []zapcore.Field {
+	for i := range fields {
+		if fields[i].Type != zapcore.ReflectType {
+			continue
+		}
+		f, ok := taggedField(fields[i].Key, fields[i].Interface)
+		if !ok {
+			continue
+		}
+
+		tagged := make([]zapcore.Field, len(fields))
+		copy(tagged, fields)
+		tagged[i] = f
+		for j := i + 1; j < len(tagged); j++ {
+			if tagged[j].Type != zapcore.ReflectType {
+				continue
+			}
+			if f, ok := taggedField(tagged[j].Key, tagged[j].Interface); ok {
+				tagged[j] = f
+			}
+		}
+		return tagged
+	}
+	return fields
+}
+
+// tagCore Core encoding the values having log tags by their tags
+// instead of reflecting them as JSON
+type tagCore struct {
+	zapcore.Core
+}
+
+func newTagCore(core zapcore.Core) zapcore.Core {
+	return &tagCore{core}
+}
+
+func (c *tagCore) Level() zapcore.Level {
+	return zapcore.LevelOf(c.Core)
+}
+
+func (c *tagCore) With(fields []zapcore.Field) zapcore.Core {
+	return &tagCore{c.Core.With(taggedFields(fields))}
+}
+
+func (c *tagCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *tagCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, taggedFields(fields))
+}
+
+// taggedObject Struct or map encoded by the log tags
+type taggedObject struct {
+	v     reflect.Value
+	depth int
+}
+
+func (o taggedObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	v := o.v
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Struct:
+		return encodeStruct(enc, v, o.depth)
+	case reflect.Map:
+		return encodeMap(enc, v, o.depth)
+	}
+	return fmt.Errorf("logger: can't encode %s as an object", v.Type())
+}
+
+// taggedArray Slice or array whose elements are encoded by the log tags
+type taggedArray struct {
+	v     reflect.Value
+	depth int
+}
+
+func (a taggedArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	v := a.v
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+
+	var err error
+	for i := 0; i < v.Len(); i++ {
+		if elemErr := encodeValue(arrayValueEncoder{enc}, v.Index(i), a.depth); elemErr != nil && err == nil {
+			err = elemErr
+		}
+	}
+	return err
+}
+
+func encodeStruct(enc zapcore.ObjectEncoder, v reflect.Value, depth int) error {
+	var err error
+	for _, f := range cachedStructInfo(v.Type()).fields {
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+
+		switch {
+		case f.redact:
+			enc.AddString(f.name, DefaultRedactMask)
+		case f.hash:
+			enc.AddString(f.name, hashValue(currentHashKey(), valueString(fv)))
+		default:
+			if fieldErr := encodeValue(objectValueEncoder{enc, f.name}, fv, depth); fieldErr != nil && err == nil {
+				err = fieldErr
+			}
+		}
+	}
+	return err
+}
+
+func encodeMap(enc zapcore.ObjectEncoder, v reflect.Value, depth int) error {
+	keys := v.MapKeys()
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		if k.Kind() == reflect.String {
+			names[i] = k.String()
+		} else {
+			names[i] = fmt.Sprint(k.Interface())
+		}
+	}
+
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return names[order[i]] < names[order[j]] })
+
+	var err error
+	for _, i := range order {
+		if valueErr := encodeValue(objectValueEncoder{enc, names[i]}, v.MapIndex(keys[i]), depth); valueErr != nil && err == nil {
+			err = valueErr
+		}
+	}
+	return err
+}
+
+// fieldByIndex Get the nested field, false if an embedded pointer on the way is nil
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, idx := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(idx)
+	}
+	return v, true
+}
+
+// valueEncoder Adds a value to an object under a key or to an array
+type valueEncoder interface {
+	addObject(zapcore.ObjectMarshaler) error
+	addArray(zapcore.ArrayMarshaler) error
+	addReflected(interface{}) error
+	addString(string)
+	addBool(bool)
+	addInt64(int64)
+	addUint64(uint64)
+	addFloat64(float64)
+	addFloat32(float32)
+	addComplex128(complex128)
+	addTime(time.Time)
+	addDuration(time.Duration)
+}
+
+// encodeValue Encode the value, the structs & maps it holds are encoded by their log tags
+func encodeValue(enc valueEncoder, v reflect.Value, depth int) error {
+	if !v.IsValid() {
+		return enc.addReflected(nil)
+	}
+	if depth >= maxTagDepth {
+		enc.addString(fmt.Sprintf("<%s nested too deeply>", v.Type()))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return enc.addReflected(nil)
+		}
+	}
+
+	if v.CanInterface() {
+		switch x := v.Interface().(type) {
+		case zapcore.ObjectMarshaler:
+			return enc.addObject(x)
+		case zapcore.ArrayMarshaler:
+			return enc.addArray(x)
+		case time.Time:
+			enc.addTime(x)
+			return nil
+		case time.Duration:
+			enc.addDuration(x)
+			return nil
+		case error:
+			enc.addString(x.Error())
+			return nil
+		case json.Marshaler:
+			return enc.addReflected(x)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return encodeValue(enc, v.Elem(), depth)
+	case reflect.Struct, reflect.Map:
+		return enc.addObject(taggedObject{v, depth + 1})
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 && v.CanInterface() {
+			// encoded as base64 like encoding/json
+			return enc.addReflected(v.Interface())
+		}
+		return enc.addArray(taggedArray{v, depth + 1})
+	case reflect.String:
+		enc.addString(v.String())
+	case reflect.Bool:
+		enc.addBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		enc.addInt64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		enc.addUint64(v.Uint())
+	case reflect.Float32:
+		enc.addFloat32(float32(v.Float()))
+	case reflect.Float64:
+		enc.addFloat64(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		enc.addComplex128(v.Complex())
+	default:
+		// channels & functions can't be encoded
+		enc.addString(v.Type().String())
+	}
+	return nil
+}
+
+// isEmptyValue Check whether the value is omitted by omitempty
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// valueString Get the string hashed for the value
+func valueString(v reflect.Value) string {
+	if v.Kind() == reflect.String {
+		return v.String()
+	}
+	if !v.CanInterface() {
+		return fmt.Sprint(v)
+	}
+	raw, err := json.Marshal(v.Interface())
+	if err != nil {
+		return fmt.Sprint(v.Interface())
+	}
+	return string(raw)
+}
+
+type objectValueEncoder struct {
+	enc zapcore.ObjectEncoder
+	key string
+}
+
+func (e objectValueEncoder) addObject(m zapcore.ObjectMarshaler) error {
+	return e.enc.AddObject(e.key, m)
+}
+func (e objectValueEncoder) addArray(m zapcore.ArrayMarshaler) error { return e.enc.AddArray(e.key, m) }
+func (e objectValueEncoder) addReflected(v interface{}) error        { return e.enc.AddReflected(e.key, v) }
+func (e objectValueEncoder) addString(v string)                      { e.enc.AddString(e.key, v) }
+func (e objectValueEncoder) addBool(v bool)                          { e.enc.AddBool(e.key, v) }
+func (e objectValueEncoder) addInt64(v int64)                        { e.enc.AddInt64(e.key, v) }
+func (e objectValueEncoder) addUint64(v uint64)                      { e.enc.AddUint64(e.key, v) }
+func (e objectValueEncoder) addFloat64(v float64)                    { e.enc.AddFloat64(e.key, v) }
+func (e objectValueEncoder) addFloat32(v float32)                    { e.enc.AddFloat32(e.key, v) }
+func (e objectValueEncoder) addComplex128(v complex128)              { e.enc.AddComplex128(e.key, v) }
+func (e objectValueEncoder) addTime(v time.Time)                     { e.enc.AddTime(e.key, v) }
+func (e objectValueEncoder) addDuration(v time.Duration)             { e.enc.AddDuration(e.key, v) }
+
+type arrayValueEncoder struct {
+	enc zapcore.ArrayEncoder
+}
+
+func (e arrayValueEncoder) addObject(m zapcore.ObjectMarshaler) error { return e.enc.AppendObject(m) }
+func (e arrayValueEncoder) addArray(m zapcore.ArrayMarshaler) error   { return e.enc.AppendArray(m) }
+func (e arrayValueEncoder) addReflected(v interface{}) error          { return e.enc.AppendReflected(v) }
+func (e arrayValueEncoder) addString(v string)                        { e.enc.AppendString(v) }
+func (e arrayValueEncoder) addBool(v bool)                            { e.enc.AppendBool(v) }
+func (e arrayValueEncoder) addInt64(v int64)                          { e.enc.AppendInt64(v) }
+func (e arrayValueEncoder) addUint64(v uint64)                        { e.enc.AppendUint64(v) }
+func (e arrayValueEncoder) addFloat64(v float64)                      { e.enc.AppendFloat64(v) }
+func (e arrayValueEncoder) addFloat32(v float32)                      { e.enc.AppendFloat32(v) }
+func (e arrayValueEncoder) addComplex128(v complex128)                { e.enc.AppendComplex128(v) }
+func (e arrayValueEncoder) addTime(v time.Time)                       { e.enc.AppendTime(v) }
+func (e arrayValueEncoder) addDuration(v time.Duration)               { e.enc.AppendDuration(v) }
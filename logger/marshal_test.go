@@ -0,0 +1,205 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+type testAudit struct {
+	CreatedBy string    `log:"created_by"`
+	CreatedAt time.Time `log:"created_at,omitempty"`
+}
+
+type testOrderItem struct {
+	SKU      string `log:"sku"`
+	Quantity int    `log:"qty"`
+}
+
+type testOrder struct {
+	testAudit
+
+	ID       int64             `log:"id"`
+	Coupon   string            `log:"coupon,omitempty"`
+	Card     string            `log:"card,redact"`
+	Email    string            `log:"email,hash"`
+	Password string            `log:"-"`
+	Note     string            `json:"note"`
+	Internal string            `json:"-"`
+	Items    []testOrderItem   `log:"items"`
+	Labels   map[string]string `log:"labels,omitempty"`
+	Parent   *testOrder        `log:"parent"`
+	Timeout  time.Duration     `log:"timeout"`
+	secret   string
+}
+
+// testUntagged Struct without log tags holding a tagged one
+type testUntagged struct {
+	Name  string
+	Order testOrder
+}
+
+func TestTaggedObject(t *testing.T) {
+	t.Parallel()
+
+	order := testOrder{
+		testAudit: testAudit{CreatedBy: "admin"},
+		ID:        42,
+		Card:      "4111111111111111",
+		Email:     "john@example.com",
+		Password:  "secret",
+		Note:      "leave at the door",
+		Internal:  "internal",
+		Items:     []testOrderItem{{SKU: "apple", Quantity: 3}},
+		Timeout:   time.Second,
+		secret:    "secret",
+	}
+	expectedOrder := map[string]interface{}{
+		"created_by": "admin",
+		"id":         json.Number("42"),
+		"card":       "[REDACTED]",
+		"email":      hashValue(nil, "john@example.com"),
+		"note":       "leave at the door",
+		"items":      []interface{}{map[string]interface{}{"sku": "apple", "qty": json.Number("3")}},
+		"parent":     nil,
+		"timeout":    json.Number("1"),
+	}
+
+	testCases := []struct {
+		name     string
+		log      func(ctx context.Context)
+		expected interface{}
+	}{
+		{
+			name:     "kv",
+			log:      func(ctx context.Context) { InfoKV(ctx, "created", "order", order) },
+			expected: expectedOrder,
+		},
+		{
+			name:     "pointer kv",
+			log:      func(ctx context.Context) { InfoKV(ctx, "created", "order", &order) },
+			expected: expectedOrder,
+		},
+		{
+			name:     "sugared logger",
+			log:      func(ctx context.Context) { FromContext(ctx).Infow("created", "order", order) },
+			expected: expectedOrder,
+		},
+		{
+			name:     "AddKV",
+			log:      func(ctx context.Context) { Info(AddKV(ctx, "order", order), "created") },
+			expected: expectedOrder,
+		},
+		{
+			name:     "slice",
+			log:      func(ctx context.Context) { InfoKV(ctx, "created", "order", []testOrder{order}) },
+			expected: []interface{}{expectedOrder},
+		},
+		{
+			name: "nested in an untagged struct",
+			log: func(ctx context.Context) {
+				InfoKV(ctx, "created", "order", testUntagged{Name: "untagged", Order: order})
+			},
+			expected: map[string]interface{}{"Name": "untagged", "Order": expectedOrder},
+		},
+		{
+			name: "Object",
+			log: func(ctx context.Context) {
+				FromContext(ctx).Infow("created", Object("order", testOrderItem{SKU: "pear"}))
+			},
+			expected: map[string]interface{}{"sku": "pear", "qty": json.Number("0")},
+		},
+		{
+			name:     "Object nil",
+			log:      func(ctx context.Context) { FromContext(ctx).Infow("created", Object("order", nil)) },
+			expected: map[string]interface{}{},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := bytes.Buffer{}
+			tc.log(ToContext(context.Background(), NewWithSink(zapcore.DebugLevel, &buf)))
+
+			dec := json.NewDecoder(&buf)
+			dec.UseNumber()
+			var decoded map[string]interface{}
+			require.NoError(t, dec.Decode(&decoded))
+
+			require.Equal(t, tc.expected, decoded["order"])
+		})
+	}
+}
+
+func TestTaggedObjectWithRedaction(t *testing.T) {
+	t.Parallel()
+
+	buf := bytes.Buffer{}
+	l := NewWithSink(zapcore.DebugLevel, &buf, WithRedaction(RedactConfig{Rules: []RedactRule{{Key: "note"}}}))
+	InfoKV(ToContext(context.Background(), l), "created", "order", testOrder{Password: "secret", Note: "door code 1234"})
+
+	require.NotContains(t, buf.String(), "secret")
+	require.NotContains(t, buf.String(), "door code")
+	require.Contains(t, buf.String(), `"note":"[REDACTED]"`)
+}
+
+func TestTaggedObjectCycle(t *testing.T) {
+	t.Parallel()
+
+	order := &testOrder{ID: 1}
+	order.Parent = order
+
+	buf := bytes.Buffer{}
+	FromContext(ToContext(context.Background(), NewWithSink(zapcore.DebugLevel, &buf))).Infow("created", "order", order)
+	require.Contains(t, buf.String(), "nested too deeply")
+}
+
+func TestCachedStructInfo(t *testing.T) {
+	t.Parallel()
+
+	info := cachedStructInfo(reflect.TypeFor[testOrder]())
+	require.Same(t, info, cachedStructInfo(reflect.TypeFor[testOrder]()))
+
+	names := make([]string, 0, len(info.fields))
+	for _, f := range info.fields {
+		names = append(names, f.name)
+	}
+	require.Equal(t, []string{"created_by", "created_at", "id", "coupon", "card", "email", "note", "items", "labels", "parent", "timeout"}, names)
+
+	require.True(t, hasLogTags(reflect.TypeFor[[]testUntagged]()))
+	require.False(t, hasLogTags(reflect.TypeFor[struct{ Name string }]()))
+}
+
+func TestSetHashKey(t *testing.T) {
+	t.Cleanup(func() { SetHashKey(nil) })
+
+	type user struct {
+		Email string `log:"email,hash"`
+	}
+	encoded := func() string {
+		enc := zapcore.NewMapObjectEncoder()
+		require.NoError(t, MarshalObject(user{Email: "john@example.com"}).MarshalLogObject(enc))
+		return enc.Fields["email"].(string)
+	}
+
+	plain := hashValue(nil, "john@example.com")
+	require.Equal(t, plain, encoded())
+	require.Equal(t, plain, Hash("john@example.com"))
+
+	SetHashKey([]byte("secret"))
+	keyed := hashValue([]byte("secret"), "john@example.com")
+	require.Equal(t, keyed, encoded())
+	require.Equal(t, keyed, Hash("john@example.com"))
+	// the redaction without a key of its own shares it
+	require.Equal(t, keyed, newRedactor(RedactConfig{}).hash("john@example.com"))
+	require.NotEqual(t, keyed, newRedactor(RedactConfig{HashKey: []byte("other")}).hash("john@example.com"))
+}
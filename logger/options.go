@@ -5,9 +5,18 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
+// coreWithLevel Core logging at its own level regardless of the wrapped one
 type coreWithLevel struct {
 	zapcore.Core
-	level zapcore.Level
+	level zapcore.LevelEnabler
+}
+
+func (c *coreWithLevel) Level() zapcore.Level {
+	return zapcore.LevelOf(c.level)
+}
+
+func (c *coreWithLevel) levelFor(string) zapcore.Level {
+	return c.Level()
 }
 
 func (c *coreWithLevel) Enabled(l zapcore.Level) bool {
@@ -21,6 +30,10 @@ func (c *coreWithLevel) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapc
 	return ce
 }
 
+func (c *coreWithLevel) usesSpan() bool {
+	return usesSpan(c.Core)
+}
+
 func (c *coreWithLevel) With(fields []zapcore.Field) zapcore.Core {
 	return &coreWithLevel{
 		c.Core.With(fields),
@@ -28,9 +41,75 @@ func (c *coreWithLevel) With(fields []zapcore.Field) zapcore.Core {
 	}
 }
 
+// coreWithRelativeLevel Core logging at a level shifted from the wrapped one,
+// so it keeps following the level changes of the parent
+type coreWithRelativeLevel struct {
+	zapcore.Core
+	delta int
+}
+
+func (c *coreWithRelativeLevel) Level() zapcore.Level {
+	return shiftLevel(zapcore.LevelOf(c.Core), c.delta)
+}
+
+func (c *coreWithRelativeLevel) levelFor(name string) zapcore.Level {
+	return shiftLevel(effectiveLevel(c.Core, name), c.delta)
+}
+
+func (c *coreWithRelativeLevel) Enabled(l zapcore.Level) bool {
+	return c.Level().Enabled(l)
+}
+
+func (c *coreWithRelativeLevel) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.levelFor(ent.LoggerName).Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *coreWithRelativeLevel) usesSpan() bool {
+	return usesSpan(c.Core)
+}
+
+func (c *coreWithRelativeLevel) With(fields []zapcore.Field) zapcore.Core {
+	return &coreWithRelativeLevel{
+		c.Core.With(fields),
+		c.delta,
+	}
+}
+
+// shiftLevel Move the level by delta steps, staying within debug..fatal
+func shiftLevel(lvl zapcore.Level, delta int) zapcore.Level {
+	shifted := int(lvl) + delta
+	if shifted < int(zapcore.DebugLevel) {
+		return zapcore.DebugLevel
+	}
+	if shifted > int(zapcore.FatalLevel) {
+		return zapcore.FatalLevel
+	}
+	return zapcore.Level(shifted)
+}
+
 // WithLevel option that allows you to create a logger with a specified level from an existing one
 func WithLevel(lvl zapcore.Level) zap.Option {
 	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
 		return &coreWithLevel{core, lvl}
 	})
 }
+
+// WithSharedLevel option that binds the logger to the shared level of the name (see SharedLevel)
+// instead of the level of the existing one
+func WithSharedLevel(name string) zap.Option {
+	lvl := SharedLevel(name)
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &coreWithLevel{core, lvl}
+	})
+}
+
+// WithRelativeLevel option that logs delta levels above (positive) or below (negative)
+// the existing logger, e.g. WithRelativeLevel(-1) logs at debug while the parent is at info
+func WithRelativeLevel(delta int) zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &coreWithRelativeLevel{core, delta}
+	})
+}
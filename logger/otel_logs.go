@@ -0,0 +1,403 @@
+package logger
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// spanKey Key of the field carrying the context span to the OpenTelemetry cores,
+// skipped by the encoders
+const spanKey = "otel_span"
+
+// Severity OpenTelemetry log severity number
+type Severity int
+
+// The severities the zap levels are mapped to
+const (
+	SeverityUndefined Severity = 0
+	SeverityDebug     Severity = 5
+	SeverityInfo      Severity = 9
+	SeverityWarn      Severity = 13
+	SeverityError     Severity = 17
+	SeverityFatal1    Severity = 21
+	SeverityFatal2    Severity = 22
+	SeverityFatal3    Severity = 23
+)
+
+// LogRecord OpenTelemetry log record of an entry
+type LogRecord struct {
+	Timestamp         time.Time
+	ObservedTimestamp time.Time
+	Severity          Severity
+	SeverityText      string
+	Body              string
+	// LoggerName name of the logger (see WithName), the instrumentation scope of the record
+	LoggerName string
+	// Attributes the fields of the logger & the entry, the nested objects are flattened
+	// into dotted keys (e.g. user.id) and the other values are encoded as JSON
+	Attributes []attribute.KeyValue
+	TraceID    trace.TraceID
+	SpanID     trace.SpanID
+	TraceFlags trace.TraceFlags
+}
+
+// otelExportTimeout Deadline of the context passed to the exporter calls
+const otelExportTimeout = 5 * time.Second
+
+// LogExporter Exports the log records to an OpenTelemetry pipeline. Export is called
+// synchronously by every log call so it must not block: queue the records
+// (e.g. a batching processor feeding an OTLP exporter) instead of sending them.
+// The context is canceled after 5 seconds.
+// An exporter with a ForceFlush(context.Context) error method is flushed by Sync.
+type LogExporter interface {
+	Export(ctx context.Context, records []LogRecord) error
+}
+
+// InMemoryLogExporter Exporter keeping the records in memory, for tests
+type InMemoryLogExporter struct {
+	mu      sync.Mutex
+	records []LogRecord
+}
+
+// NewInMemoryLogExporter create a new in-memory exporter
+func NewInMemoryLogExporter() *InMemoryLogExporter {
+	return &InMemoryLogExporter{}
+}
+
+// Export Keep the records
+func (e *InMemoryLogExporter) Export(_ context.Context, records []LogRecord) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.records = append(e.records, records...)
+	return nil
+}
+
+// Records Get a copy of the records exported so far
+func (e *InMemoryLogExporter) Records() []LogRecord {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]LogRecord(nil), e.records...)
+}
+
+// Reset Forget the records exported so far
+func (e *InMemoryLogExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.records = nil
+}
+
+// WithOTelLogs Export the entries as OpenTelemetry log records alongside the logger output:
+//
+//	logger.New(nil, logger.WithOTelLogs(exporter))
+//
+// The records hold the fields of the logger (AddKV, WithKV, WithFields...) & the entry
+// as attributes and the trace ids & flags of the context span.
+// The records are redacted when the option is passed before WithRedaction.
+func WithOTelLogs(exporter LogExporter) zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &otelCore{Core: core, exporter: exporter}
+	})
+}
+
+// spanField Field carrying the context span to the OpenTelemetry cores,
+// only attached to the loggers whose cores use it (see usesSpan)
+func spanField(span trace.Span) zap.Field {
+	return zap.Field{Key: spanKey, Type: zapcore.SkipType, Interface: span}
+}
+
+// spanUser Core using the context span, the cores wrapping
+// another one report whether the wrapped one uses it
+type spanUser interface {
+	usesSpan() bool
+}
+
+// usesSpan Whether a core of the chain uses the context span (see WithOTelLogs
+// & WithSpanEvents), the cores unknown to the package hide the ones they wrap
+func usesSpan(core zapcore.Core) bool {
+	c, ok := core.(spanUser)
+	return ok && c.usesSpan()
+}
+
+// withoutSpan Drop the span field unless the wrapped core uses it too,
+// so the cores unaware of it never see the span
+func withoutSpan(core zapcore.Core, fields []zapcore.Field) []zapcore.Field {
+	if usesSpan(core) {
+		return fields
+	}
+
+	for i := range fields {
+		if fields[i].Type == zapcore.SkipType && fields[i].Key == spanKey {
+			kept := make([]zapcore.Field, 0, len(fields)-1)
+			kept = append(kept, fields[:i]...)
+			for _, f := range fields[i+1:] {
+				if f.Type != zapcore.SkipType || f.Key != spanKey {
+					kept = append(kept, f)
+				}
+			}
+			return kept
+		}
+	}
+	return fields
+}
+
+// otelCore Core exporting the entries written by the wrapped one as log records
+type otelCore struct {
+	zapcore.Core
+	exporter LogExporter
+	fields   []zapcore.Field
+	span     trace.Span
+}
+
+func (c *otelCore) Level() zapcore.Level {
+	return zapcore.LevelOf(c.Core)
+}
+
+func (c *otelCore) levelFor(name string) zapcore.Level {
+	return effectiveLevel(c.Core, name)
+}
+
+func (c *otelCore) usesSpan() bool {
+	return true
+}
+
+func (c *otelCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.Core = c.Core.With(withoutSpan(c.Core, fields))
+	clone.fields = append(c.fields[:len(c.fields):len(c.fields)], fields...)
+	if span, ok := spanOf(fields); ok {
+		clone.span = span
+	}
+	return &clone
+}
+
+func (c *otelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.levelFor(ent.LoggerName).Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *otelCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	err := c.Core.Write(ent, fields)
+
+	var spanCtx trace.SpanContext
+	if span, ok := spanOf(fields); ok {
+		spanCtx = span.SpanContext()
+	} else if c.span != nil {
+		spanCtx = c.span.SpanContext()
+	}
+
+	record := LogRecord{
+		Timestamp:         ent.Time,
+		ObservedTimestamp: time.Now(),
+		Severity:          severityOf(ent.Level),
+		SeverityText:      ent.Level.CapitalString(),
+		Body:              ent.Message,
+		LoggerName:        ent.LoggerName,
+		Attributes:        entryAttributes(ent, c.fields, fields),
+		TraceID:           spanCtx.TraceID(),
+		SpanID:            spanCtx.SpanID(),
+		TraceFlags:        spanCtx.TraceFlags(),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), otelExportTimeout)
+	defer cancel()
+	return multierr.Append(err, c.exporter.Export(ctx, []LogRecord{record}))
+}
+
+func (c *otelCore) Sync() error {
+	err := c.Core.Sync()
+	if flusher, ok := c.exporter.(interface{ ForceFlush(context.Context) error }); ok {
+		ctx, cancel := context.WithTimeout(context.Background(), otelExportTimeout)
+		defer cancel()
+		err = multierr.Append(err, flusher.ForceFlush(ctx))
+	}
+	return err
+}
+
+// spanOf Get the last span carried by the fields
+func spanOf(fields []zapcore.Field) (trace.Span, bool) {
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fields[i].Type != zapcore.SkipType || fields[i].Key != spanKey {
+			continue
+		}
+		if span, ok := fields[i].Interface.(trace.Span); ok {
+			return span, true
+		}
+	}
+	return nil, false
+}
+
+// severityOf Map the level to the severity like the OpenTelemetry zap bridge
+func severityOf(l zapcore.Level) Severity {
+	switch l {
+	case zapcore.DebugLevel:
+		return SeverityDebug
+	case zapcore.InfoLevel:
+		return SeverityInfo
+	case zapcore.WarnLevel:
+		return SeverityWarn
+	case zapcore.ErrorLevel:
+		return SeverityError
+	case zapcore.DPanicLevel:
+		return SeverityFatal1
+	case zapcore.PanicLevel:
+		return SeverityFatal2
+	case zapcore.FatalLevel:
+		return SeverityFatal3
+	}
+	return SeverityUndefined
+}
+
+// entryAttributes Get the attributes of the context & the entry fields sorted by key,
+// a field overrides the previous one with the same key
+func entryAttributes(ent zapcore.Entry, contextFields, fields []zapcore.Field) []attribute.KeyValue {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range taggedFields(contextFields) {
+		f.AddTo(enc)
+	}
+	for _, f := range taggedFields(fields) {
+		f.AddTo(enc)
+	}
+
+	attrs := make([]attribute.KeyValue, 0, len(enc.Fields)+4)
+	attrs = appendAttributes(attrs, "", enc.Fields)
+	if ent.Caller.Defined {
+		attrs = append(attrs,
+			attribute.String("code.filepath", ent.Caller.File),
+			attribute.Int("code.lineno", ent.Caller.Line),
+			attribute.String("code.function", ent.Caller.Function),
+		)
+	}
+	if ent.Stack != "" {
+		attrs = append(attrs, attribute.String("code.stacktrace", ent.Stack))
+	}
+	return attrs
+}
+
+func appendAttributes(attrs []attribute.KeyValue, prefix string, fields map[string]interface{}) []attribute.KeyValue {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		key := prefix + k
+		if nested, ok := fields[k].(map[string]interface{}); ok {
+			attrs = appendAttributes(attrs, key+".", nested)
+			continue
+		}
+		attrs = append(attrs, attributeOf(key, fields[k]))
+	}
+	return attrs
+}
+
+// attributeOf Convert the value added by a field to the map encoder
+func attributeOf(key string, v interface{}) attribute.KeyValue {
+	switch x := v.(type) {
+	case string:
+		return attribute.String(key, x)
+	case bool:
+		return attribute.Bool(key, x)
+	case int:
+		return attribute.Int(key, x)
+	case int8:
+		return attribute.Int64(key, int64(x))
+	case int16:
+		return attribute.Int64(key, int64(x))
+	case int32:
+		return attribute.Int64(key, int64(x))
+	case int64:
+		return attribute.Int64(key, x)
+	case uint:
+		return uintAttribute(key, uint64(x))
+	case uint8:
+		return attribute.Int64(key, int64(x))
+	case uint16:
+		return attribute.Int64(key, int64(x))
+	case uint32:
+		return attribute.Int64(key, int64(x))
+	case uint64:
+		return uintAttribute(key, x)
+	case uintptr:
+		return uintAttribute(key, uint64(x))
+	case float32:
+		return attribute.Float64(key, float64(x))
+	case float64:
+		return attribute.Float64(key, x)
+	case time.Time:
+		return attribute.String(key, x.Format(time.RFC3339Nano))
+	case time.Duration:
+		return attribute.String(key, x.String())
+	case []byte:
+		return attribute.String(key, base64.StdEncoding.EncodeToString(x))
+	case complex64, complex128:
+		return attribute.String(key, fmt.Sprint(x))
+	case []interface{}:
+		if attr, ok := sliceAttribute(key, x); ok {
+			return attr
+		}
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return attribute.String(key, fmt.Sprint(v))
+	}
+	return attribute.String(key, string(raw))
+}
+
+func uintAttribute(key string, v uint64) attribute.KeyValue {
+	if v > math.MaxInt64 {
+		return attribute.String(key, fmt.Sprint(v))
+	}
+	return attribute.Int64(key, int64(v))
+}
+
+// sliceAttribute Get the slice attribute of an array of strings, bools, ints or floats,
+// false if the elements are mixed or of another type
+func sliceAttribute(key string, values []interface{}) (attribute.KeyValue, bool) {
+	if len(values) == 0 {
+		return attribute.KeyValue{}, false
+	}
+
+	switch values[0].(type) {
+	case string:
+		return typedSliceAttribute(key, values, attribute.StringSlice)
+	case bool:
+		return typedSliceAttribute(key, values, attribute.BoolSlice)
+	case int64:
+		return typedSliceAttribute(key, values, attribute.Int64Slice)
+	case int:
+		return typedSliceAttribute(key, values, attribute.IntSlice)
+	case float64:
+		return typedSliceAttribute(key, values, attribute.Float64Slice)
+	}
+	return attribute.KeyValue{}, false
+}
+
+func typedSliceAttribute[T any](key string, values []interface{}, attr func(string, []T) attribute.KeyValue) (attribute.KeyValue, bool) {
+	typed := make([]T, len(values))
+	for i, v := range values {
+		t, ok := v.(T)
+		if !ok {
+			return attribute.KeyValue{}, false
+		}
+		typed[i] = t
+	}
+	return attr(key, typed), true
+}
@@ -0,0 +1,221 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestWithOTelLogs(t *testing.T) {
+	t.Parallel()
+
+	buf := bytes.Buffer{}
+	exporter := NewInMemoryLogExporter()
+	l := NewWithSink(zapcore.InfoLevel, &buf, WithOTelLogs(exporter))
+
+	spanCtx := testSpanContext(t, "a48b167265f65931").WithTraceFlags(trace.FlagsSampled)
+	ctx := trace.ContextWithSpanContext(ToContext(context.Background(), l), spanCtx)
+	ctx = WithName(AddKV(ctx, "request_id", "42", "attempt", 1), "payments")
+
+	Debug(ctx, "hidden")
+	ErrorKV(ctx, "payment failed",
+		"attempt", 2,
+		"tags", []string{"card", "retry"},
+		"user", MarshalObject(struct {
+			ID       int64  `log:"id"`
+			Password string `log:"-"`
+		}{ID: 5, Password: "secret"}),
+		"error", errors.New("card declined"),
+	)
+
+	records := exporter.Records()
+	require.Len(t, records, 1)
+
+	record := records[0]
+	require.Equal(t, SeverityError, record.Severity)
+	require.Equal(t, "ERROR", record.SeverityText)
+	require.Equal(t, "payment failed", record.Body)
+	require.Equal(t, "payments", record.LoggerName)
+	require.Equal(t, spanCtx.TraceID(), record.TraceID)
+	require.Equal(t, spanCtx.SpanID(), record.SpanID)
+	require.True(t, record.TraceFlags.IsSampled())
+	require.False(t, record.Timestamp.IsZero())
+	require.Equal(t, []attribute.KeyValue{
+		attribute.Int64("attempt", 2),
+		attribute.String("error", "card declined"),
+		attribute.String("request_id", "42"),
+		attribute.String("span_id", "a48b167265f65931"),
+		attribute.StringSlice("tags", []string{"card", "retry"}),
+		attribute.String("trace_id", "55e02c160e0dbd1b441bf1d5dc3ea3d5"),
+		attribute.Int64("user.id", 5),
+	}, record.Attributes)
+
+	// the logger output is unchanged
+	require.Contains(t, buf.String(), `"message":"payment failed"`)
+	require.NotContains(t, buf.String(), spanKey)
+	require.NotContains(t, buf.String(), "hidden")
+	require.Equal(t, zapcore.InfoLevel, LevelFromContext(ctx))
+}
+
+func TestWithOTelLogsRedaction(t *testing.T) {
+	t.Parallel()
+
+	exporter := NewInMemoryLogExporter()
+	l := NewWithSink(zapcore.DebugLevel, &bytes.Buffer{},
+		WithOTelLogs(exporter),
+		WithRedaction(RedactConfig{Rules: []RedactRule{{Key: "password", Action: RedactDrop}, {Key: "card"}}}),
+	)
+
+	l.Infow("login", "user", "john", "password", "secret", "card", "4111111111111111")
+
+	records := exporter.Records()
+	require.Len(t, records, 1)
+	require.Equal(t, []attribute.KeyValue{
+		attribute.String("card", DefaultRedactMask),
+		attribute.String("user", "john"),
+	}, records[0].Attributes)
+
+	exporter.Reset()
+	require.Empty(t, exporter.Records())
+}
+
+func TestSpanFieldOnlyWithOTelCores(t *testing.T) {
+	t.Parallel()
+
+	spanCtx := testSpanContext(t, "a48b167265f65931")
+
+	testCases := []struct {
+		name    string
+		options []zap.Option
+	}{
+		{name: "no otel core"},
+		{name: "otel core", options: []zap.Option{WithOTelLogs(NewInMemoryLogExporter())}},
+		{name: "otel cores", options: []zap.Option{WithOTelLogs(NewInMemoryLogExporter()), WithSpanEvents(zapcore.WarnLevel)}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			core, logs := observer.New(zapcore.DebugLevel)
+			l := zap.New(core, tc.options...).Sugar()
+			ctx := trace.ContextWithSpanContext(ToContext(context.Background(), l), spanCtx)
+
+			Info(ctx, "message")
+
+			entries := logs.All()
+			require.Len(t, entries, 1)
+			require.Equal(t, "a48b167265f65931", entries[0].ContextMap()["span_id"])
+			for _, f := range entries[0].Context {
+				require.NotEqual(t, spanKey, f.Key)
+			}
+		})
+	}
+}
+
+// deadlineExporter Exporter recording the deadlines of the calls
+type deadlineExporter struct {
+	deadlines []time.Time
+}
+
+func (e *deadlineExporter) Export(ctx context.Context, _ []LogRecord) error {
+	deadline, _ := ctx.Deadline()
+	e.deadlines = append(e.deadlines, deadline)
+	return nil
+}
+
+func (e *deadlineExporter) ForceFlush(ctx context.Context) error {
+	return e.Export(ctx, nil)
+}
+
+func TestWithOTelLogsDeadline(t *testing.T) {
+	t.Parallel()
+
+	exporter := &deadlineExporter{}
+	l := NewWithSink(zapcore.InfoLevel, &bytes.Buffer{}, WithOTelLogs(exporter))
+
+	l.Info("message")
+	require.NoError(t, l.Sync())
+
+	require.Len(t, exporter.deadlines, 2)
+	for _, deadline := range exporter.deadlines {
+		require.WithinDuration(t, time.Now().Add(otelExportTimeout), deadline, time.Second)
+	}
+}
+
+func TestSeverityOf(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		level    zapcore.Level
+		expected Severity
+	}{
+		{zapcore.DebugLevel, SeverityDebug},
+		{zapcore.InfoLevel, SeverityInfo},
+		{zapcore.WarnLevel, SeverityWarn},
+		{zapcore.ErrorLevel, SeverityError},
+		{zapcore.DPanicLevel, SeverityFatal1},
+		{zapcore.PanicLevel, SeverityFatal2},
+		{zapcore.FatalLevel, SeverityFatal3},
+		{zapcore.InvalidLevel, SeverityUndefined},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.level.String(), func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.expected, severityOf(tc.level))
+		})
+	}
+}
+
+func TestEntryAttributes(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		fields   []zapcore.Field
+		expected []attribute.KeyValue
+	}{
+		{
+			name:     "large uint",
+			fields:   []zapcore.Field{zap.Uint64("n", 1<<63)},
+			expected: []attribute.KeyValue{attribute.String("n", "9223372036854775808")},
+		},
+		{
+			name:     "mixed array",
+			fields:   []zapcore.Field{zap.Any("a", []interface{}{1, "b"})},
+			expected: []attribute.KeyValue{attribute.String("a", `[1,"b"]`)},
+		},
+		{
+			name:     "namespace",
+			fields:   []zapcore.Field{zap.Namespace("http"), zap.String("method", "GET")},
+			expected: []attribute.KeyValue{attribute.String("http.method", "GET")},
+		},
+		{
+			name:     "span context",
+			fields:   []zapcore.Field{spanField(trace.SpanFromContext(context.Background()))},
+			expected: []attribute.KeyValue{},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.expected, entryAttributes(zapcore.Entry{}, nil, tc.fields))
+		})
+	}
+}
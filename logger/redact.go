@@ -0,0 +1,666 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RedactAction What is done with a sensitive value
+type RedactAction int
+
+const (
+	// RedactMask replace the value (or the matching part of a string) with the mask
+	RedactMask RedactAction = iota
+	// RedactHash replace the value (or the matching part of a string) with its hash,
+	// so equal values can still be correlated
+	RedactHash
+	// RedactDrop remove the field
+	RedactDrop
+)
+
+// DefaultRedactMask Mask used when RedactConfig.Mask is empty & for the struct fields tagged with redact
+const DefaultRedactMask = "[REDACTED]"
+
+var (
+	// PANPattern Payment card numbers, optionally grouped by spaces or dashes,
+	// the redaction rules skip the matches failing the Luhn check (e.g. order ids)
+	PANPattern = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	// EmailPattern E-mail addresses
+	EmailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// JWTPattern JSON web tokens
+	JWTPattern = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+)
+
+// RedactRule Sensitive values selected by key and/or value
+type RedactRule struct {
+	// Key glob matched against the field keys, case insensitive (e.g. "*password*"),
+	// alone it selects the whole value whatever its type
+	Key string
+	// Value pattern matched against the string values, with Key it applies
+	// only to the values of the matching keys
+	Value *regexp.Regexp
+	// Action applied to the selected values
+	Action RedactAction
+}
+
+// RedactConfig Redaction rules of WithRedaction
+type RedactConfig struct {
+	Rules []RedactRule
+	// Mask replacement of the masked values (default "[REDACTED]")
+	Mask string
+	// HashKey key of the HMAC-SHA256 used to hash the values, the key set by SetHashKey if empty.
+	// Low entropy values such as card numbers need a secret key to not be brute forced.
+	HashKey []byte
+}
+
+// WithRedaction option that masks, hashes or drops the sensitive values of the fields,
+// whether they are added by AddKV, WithKV, WithFields or passed to the log call,
+// including the ones nested in objects, arrays, maps and structs:
+//
+//	logger.New(nil, logger.WithRedaction(logger.RedactConfig{Rules: []logger.RedactRule{
+//		{Key: "*password*", Action: logger.RedactDrop},
+//		{Key: "authorization"},
+//		{Value: logger.PANPattern, Action: logger.RedactHash},
+//	}}))
+func WithRedaction(cfg RedactConfig) zap.Option {
+	r := newRedactor(cfg)
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &redactCore{core, r}
+	})
+}
+
+// redactCore Core redacting the fields before handing them to the wrapped one
+type redactCore struct {
+	zapcore.Core
+	r *redactor
+}
+
+func (c *redactCore) Level() zapcore.Level {
+	return zapcore.LevelOf(c.Core)
+}
+
+func (c *redactCore) levelFor(name string) zapcore.Level {
+	return effectiveLevel(c.Core, name)
+}
+
+func (c *redactCore) usesSpan() bool {
+	return usesSpan(c.Core)
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{c.Core.With(c.r.fields(fields)), c.r}
+}
+
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.levelFor(ent.LoggerName).Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, c.r.fields(fields))
+}
+
+// redactor Compiled redaction rules
+type redactor struct {
+	keyRules   []RedactRule
+	valueRules []valueRule
+	mask       string
+	hashKey    []byte
+}
+
+func newRedactor(cfg RedactConfig) *redactor {
+	r := &redactor{
+		mask:    cfg.Mask,
+		hashKey: cfg.HashKey,
+	}
+	if r.mask == "" {
+		r.mask = DefaultRedactMask
+	}
+
+	for _, rule := range cfg.Rules {
+		rule.Key = strings.ToLower(rule.Key)
+		if rule.Value != nil {
+			vr := valueRule{RedactRule: rule}
+			if rule.Value == PANPattern {
+				vr.valid = luhnValid
+			}
+			r.valueRules = append(r.valueRules, vr)
+		} else if rule.Key != "" {
+			r.keyRules = append(r.keyRules, rule)
+		}
+	}
+	return r
+}
+
+// valueRule Value rule whose matches are only redacted if valid accepts them
+type valueRule struct {
+	RedactRule
+	valid func(match string) bool
+}
+
+// fields Get the redacted fields, the slice is copied only if a field changes
+func (r *redactor) fields(fields []zapcore.Field) []zapcore.Field {
+	for i := range fields {
+		f, keep, changed := r.field(fields[i])
+		if !changed {
+			continue
+		}
+
+		redacted := make([]zapcore.Field, i, len(fields))
+		copy(redacted, fields[:i])
+		if keep {
+			redacted = append(redacted, f)
+		}
+		for _, f := range fields[i+1:] {
+			if f, keep, _ := r.field(f); keep {
+				redacted = append(redacted, f)
+			}
+		}
+		return redacted
+	}
+	return fields
+}
+
+// field Get the redacted field, keep is false if it must be dropped
+func (r *redactor) field(f zapcore.Field) (redacted zapcore.Field, keep, changed bool) {
+	if f.Type == zapcore.NamespaceType || f.Type == zapcore.SkipType {
+		return f, true, false
+	}
+
+	if action, ok := r.keyAction(f.Key); ok {
+		switch action {
+		case RedactMask:
+			return zap.String(f.Key, r.mask), true, true
+		case RedactHash:
+			return zap.String(f.Key, r.hash(fieldString(f))), true, true
+		default:
+			return f, false, true
+		}
+	}
+
+	switch f.Type {
+	case zapcore.StringType:
+		if s, keep := r.value(f.Key, f.String); !keep || s != f.String {
+			return zap.String(f.Key, s), keep, true
+		}
+	case zapcore.ByteStringType:
+		b := f.Interface.([]byte)
+		if s, keep := r.value(f.Key, string(b)); !keep || s != string(b) {
+			return zap.ByteString(f.Key, []byte(s)), keep, true
+		}
+	case zapcore.StringerType, zapcore.ErrorType:
+		if !r.hasValueRules(f.Key) {
+			break
+		}
+		s := fieldString(f)
+		if redacted, keep := r.value(f.Key, s); !keep || redacted != s {
+			return zap.String(f.Key, redacted), keep, true
+		}
+	case zapcore.ObjectMarshalerType, zapcore.InlineMarshalerType:
+		f.Interface = redactObject{f.Interface.(zapcore.ObjectMarshaler), r}
+		return f, true, true
+	case zapcore.ArrayMarshalerType:
+		f.Interface = redactArray{f.Interface.(zapcore.ArrayMarshaler), r, f.Key}
+		return f, true, true
+	case zapcore.ReflectType:
+		if len(r.keyRules)+len(r.valueRules) == 0 {
+			break
+		}
+		// the log tags apply before the rules
+		if tagged, ok := taggedField(f.Key, f.Interface); ok {
+			redacted, keep, _ := r.field(tagged)
+			return redacted, keep, true
+		}
+		if v, keep, changed := r.reflected(f.Key, f.Interface); changed {
+			return zap.Reflect(f.Key, v), keep, true
+		}
+	}
+	return f, true, false
+}
+
+// keyAction Get the action of the first key rule matching the key
+func (r *redactor) keyAction(key string) (RedactAction, bool) {
+	if len(r.keyRules) == 0 {
+		return 0, false
+	}
+
+	key = strings.ToLower(key)
+	for _, rule := range r.keyRules {
+		if matchKey(rule.Key, key) {
+			return rule.Action, true
+		}
+	}
+	return 0, false
+}
+
+func (r *redactor) hasValueRules(key string) bool {
+	key = strings.ToLower(key)
+	for _, rule := range r.valueRules {
+		if rule.Key == "" || matchKey(rule.Key, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// value Apply the value rules to the string of the key, false if it must be dropped
+func (r *redactor) value(key, s string) (string, bool) {
+	if len(r.valueRules) == 0 {
+		return s, true
+	}
+
+	lowerKey := strings.ToLower(key)
+	for _, rule := range r.valueRules {
+		if rule.Key != "" && !matchKey(rule.Key, lowerKey) {
+			continue
+		}
+
+		switch {
+		case rule.Action == RedactMask && rule.valid == nil:
+			s = rule.Value.ReplaceAllLiteralString(s, r.mask)
+		case rule.Action == RedactMask:
+			s = rule.Value.ReplaceAllStringFunc(s, func(match string) string {
+				if !rule.valid(match) {
+					return match
+				}
+				return r.mask
+			})
+		case rule.Action == RedactHash:
+			s = rule.Value.ReplaceAllStringFunc(s, func(match string) string {
+				if rule.valid != nil && !rule.valid(match) {
+					return match
+				}
+				return r.hash(match)
+			})
+		default:
+			for _, match := range rule.Value.FindAllString(s, -1) {
+				if rule.valid == nil || rule.valid(match) {
+					return "", false
+				}
+			}
+		}
+	}
+	return s, true
+}
+
+// luhnValid Whether the digits of the match pass the Luhn check of the card numbers
+func luhnValid(match string) bool {
+	var sum, n int
+	for i := len(match) - 1; i >= 0; i-- {
+		c := match[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		if c < '0' || c > '9' {
+			return false
+		}
+
+		d := int(c - '0')
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
+
+// reflected Redact a value encoded by reflection out of its JSON representation,
+// changed is false if no rule applies and the value is encoded as is
+func (r *redactor) reflected(key string, v interface{}) (redacted interface{}, keep, changed bool) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		// the encoder reports the error
+		return v, true, false
+	}
+
+	// numbers are kept as is, large integers don't fit in a float64
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return v, true, false
+	}
+	return r.generic(key, generic)
+}
+
+// generic Redact a JSON decoded value in place, changed is false if no rule applies
+func (r *redactor) generic(key string, v interface{}) (redacted interface{}, keep, changed bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, value := range v {
+			if action, ok := r.keyAction(k); ok {
+				switch action {
+				case RedactMask:
+					v[k] = r.mask
+				case RedactHash:
+					v[k] = r.hash(jsonString(value))
+				default:
+					delete(v, k)
+				}
+				changed = true
+				continue
+			}
+
+			redacted, keep, valueChanged := r.generic(k, value)
+			if keep {
+				v[k] = redacted
+			} else {
+				delete(v, k)
+			}
+			changed = changed || valueChanged
+		}
+		return v, true, changed
+
+	case []interface{}:
+		kept := v[:0]
+		for _, value := range v {
+			redacted, keep, valueChanged := r.generic(key, value)
+			if keep {
+				kept = append(kept, redacted)
+			}
+			changed = changed || valueChanged
+		}
+		return kept, true, changed
+
+	case string:
+		redacted, keep := r.value(key, v)
+		return redacted, keep, !keep || redacted != v
+	}
+	return v, true, false
+}
+
+func (r *redactor) hash(s string) string {
+	if len(r.hashKey) == 0 {
+		return hashValue(currentHashKey(), s)
+	}
+	return hashValue(r.hashKey, s)
+}
+
+var hashKey atomic.Pointer[[]byte]
+
+// SetHashKey Set the key of the HMAC-SHA256 hashing the struct fields tagged with hash (see Hash)
+// & the values of WithRedaction without a HashKey, safe to call while logging concurrently.
+//
+// Without a key the values are hashed with plain SHA-256, which isn't private: low entropy
+// values such as emails, phone or card numbers are brute forced from their hash.
+func SetHashKey(key []byte) {
+	key = append([]byte(nil), key...)
+	hashKey.Store(&key)
+}
+
+// currentHashKey Get the key set by SetHashKey, nil if none
+func currentHashKey() []byte {
+	if key := hashKey.Load(); key != nil {
+		return *key
+	}
+	return nil
+}
+
+// hashValue Get the truncated HMAC-SHA256 of the value, SHA-256 without a key
+func hashValue(key []byte, s string) string {
+	var h hash.Hash
+	if len(key) > 0 {
+		h = hmac.New(sha256.New, key)
+	} else {
+		h = sha256.New()
+	}
+	h.Write([]byte(s))
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// matchKey Match the lower cased key against the lower cased glob
+func matchKey(pattern, key string) bool {
+	if pattern == key {
+		return true
+	}
+	ok, _ := path.Match(pattern, key)
+	return ok
+}
+
+// fieldString Get the string value of the field, JSON for the non string ones
+func fieldString(f zapcore.Field) string {
+	switch f.Type {
+	case zapcore.StringType:
+		return f.String
+	case zapcore.ErrorType:
+		return safeString(f.Interface, func() string { return f.Interface.(error).Error() })
+	case zapcore.StringerType:
+		return safeString(f.Interface, func() string { return f.Interface.(fmt.Stringer).String() })
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	return jsonString(enc.Fields[f.Key])
+}
+
+// safeString Get the string of the error or stringer value, "<nil>" for the nil
+// values & "<PANIC=...>" when it panics, the way zap encodes them
+func safeString(v interface{}, str func() string) (s string) {
+	if v == nil {
+		return "<nil>"
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+				s = "<nil>"
+				return
+			}
+			s = fmt.Sprintf("<PANIC=%v>", err)
+		}
+	}()
+	return str()
+}
+
+func jsonString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	raw, _ := json.Marshal(v)
+	return string(raw)
+}
+
+// redactObject Object encoded with its fields redacted
+type redactObject struct {
+	m zapcore.ObjectMarshaler
+	r *redactor
+}
+
+func (o redactObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return o.m.MarshalLogObject(&redactObjectEncoder{enc, o.r})
+}
+
+// redactArray Array encoded with its elements redacted as values of the key
+type redactArray struct {
+	m   zapcore.ArrayMarshaler
+	r   *redactor
+	key string
+}
+
+func (a redactArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	return a.m.MarshalLogArray(&redactArrayEncoder{enc, a.r, a.key})
+}
+
+// redactObjectEncoder ObjectEncoder applying the rules to the keys it's given
+type redactObjectEncoder struct {
+	zapcore.ObjectEncoder
+	r *redactor
+}
+
+// add Apply the key rules to the value, add is called if none matches
+func (e *redactObjectEncoder) add(key string, value func() string, add func()) {
+	action, ok := e.r.keyAction(key)
+	if !ok {
+		add()
+		return
+	}
+
+	switch action {
+	case RedactMask:
+		e.ObjectEncoder.AddString(key, e.r.mask)
+	case RedactHash:
+		e.ObjectEncoder.AddString(key, e.r.hash(value()))
+	}
+}
+
+func (e *redactObjectEncoder) addField(f zapcore.Field) {
+	if f, keep, _ := e.r.field(f); keep {
+		f.AddTo(e.ObjectEncoder)
+	}
+}
+
+func (e *redactObjectEncoder) AddArray(key string, m zapcore.ArrayMarshaler) error {
+	e.addField(zap.Array(key, m))
+	return nil
+}
+
+func (e *redactObjectEncoder) AddObject(key string, m zapcore.ObjectMarshaler) error {
+	e.addField(zap.Object(key, m))
+	return nil
+}
+
+func (e *redactObjectEncoder) AddReflected(key string, v interface{}) error {
+	e.addField(zap.Reflect(key, v))
+	return nil
+}
+
+func (e *redactObjectEncoder) AddString(key, value string) {
+	e.addField(zap.String(key, value))
+}
+
+func (e *redactObjectEncoder) AddByteString(key string, value []byte) {
+	e.addField(zap.ByteString(key, value))
+}
+
+func (e *redactObjectEncoder) AddBinary(key string, value []byte) {
+	e.add(key, func() string { return string(value) }, func() { e.ObjectEncoder.AddBinary(key, value) })
+}
+
+func (e *redactObjectEncoder) AddBool(key string, value bool) {
+	e.add(key, func() string { return fmt.Sprint(value) }, func() { e.ObjectEncoder.AddBool(key, value) })
+}
+
+func (e *redactObjectEncoder) AddComplex128(key string, value complex128) {
+	e.add(key, func() string { return fmt.Sprint(value) }, func() { e.ObjectEncoder.AddComplex128(key, value) })
+}
+
+func (e *redactObjectEncoder) AddComplex64(key string, value complex64) {
+	e.add(key, func() string { return fmt.Sprint(value) }, func() { e.ObjectEncoder.AddComplex64(key, value) })
+}
+
+func (e *redactObjectEncoder) AddDuration(key string, value time.Duration) {
+	e.add(key, value.String, func() { e.ObjectEncoder.AddDuration(key, value) })
+}
+
+func (e *redactObjectEncoder) AddFloat64(key string, value float64) {
+	e.add(key, func() string { return fmt.Sprint(value) }, func() { e.ObjectEncoder.AddFloat64(key, value) })
+}
+
+func (e *redactObjectEncoder) AddFloat32(key string, value float32) {
+	e.add(key, func() string { return fmt.Sprint(value) }, func() { e.ObjectEncoder.AddFloat32(key, value) })
+}
+
+func (e *redactObjectEncoder) AddInt(key string, value int) {
+	e.add(key, func() string { return fmt.Sprint(value) }, func() { e.ObjectEncoder.AddInt(key, value) })
+}
+
+func (e *redactObjectEncoder) AddInt64(key string, value int64) {
+	e.add(key, func() string { return fmt.Sprint(value) }, func() { e.ObjectEncoder.AddInt64(key, value) })
+}
+
+func (e *redactObjectEncoder) AddInt32(key string, value int32) {
+	e.add(key, func() string { return fmt.Sprint(value) }, func() { e.ObjectEncoder.AddInt32(key, value) })
+}
+
+func (e *redactObjectEncoder) AddInt16(key string, value int16) {
+	e.add(key, func() string { return fmt.Sprint(value) }, func() { e.ObjectEncoder.AddInt16(key, value) })
+}
+
+func (e *redactObjectEncoder) AddInt8(key string, value int8) {
+	e.add(key, func() string { return fmt.Sprint(value) }, func() { e.ObjectEncoder.AddInt8(key, value) })
+}
+
+func (e *redactObjectEncoder) AddTime(key string, value time.Time) {
+	e.add(key, func() string { return value.Format(time.RFC3339Nano) }, func() { e.ObjectEncoder.AddTime(key, value) })
+}
+
+func (e *redactObjectEncoder) AddUint(key string, value uint) {
+	e.add(key, func() string { return fmt.Sprint(value) }, func() { e.ObjectEncoder.AddUint(key, value) })
+}
+
+func (e *redactObjectEncoder) AddUint64(key string, value uint64) {
+	e.add(key, func() string { return fmt.Sprint(value) }, func() { e.ObjectEncoder.AddUint64(key, value) })
+}
+
+func (e *redactObjectEncoder) AddUint32(key string, value uint32) {
+	e.add(key, func() string { return fmt.Sprint(value) }, func() { e.ObjectEncoder.AddUint32(key, value) })
+}
+
+func (e *redactObjectEncoder) AddUint16(key string, value uint16) {
+	e.add(key, func() string { return fmt.Sprint(value) }, func() { e.ObjectEncoder.AddUint16(key, value) })
+}
+
+func (e *redactObjectEncoder) AddUint8(key string, value uint8) {
+	e.add(key, func() string { return fmt.Sprint(value) }, func() { e.ObjectEncoder.AddUint8(key, value) })
+}
+
+func (e *redactObjectEncoder) AddUintptr(key string, value uintptr) {
+	e.add(key, func() string { return fmt.Sprint(value) }, func() { e.ObjectEncoder.AddUintptr(key, value) })
+}
+
+// redactArrayEncoder ArrayEncoder applying the value rules of the array key to its elements
+type redactArrayEncoder struct {
+	zapcore.ArrayEncoder
+	r   *redactor
+	key string
+}
+
+func (e *redactArrayEncoder) AppendString(value string) {
+	if s, keep := e.r.value(e.key, value); keep {
+		e.ArrayEncoder.AppendString(s)
+	}
+}
+
+func (e *redactArrayEncoder) AppendByteString(value []byte) {
+	if s, keep := e.r.value(e.key, string(value)); keep {
+		e.ArrayEncoder.AppendByteString([]byte(s))
+	}
+}
+
+func (e *redactArrayEncoder) AppendArray(m zapcore.ArrayMarshaler) error {
+	return e.ArrayEncoder.AppendArray(redactArray{m, e.r, e.key})
+}
+
+func (e *redactArrayEncoder) AppendObject(m zapcore.ObjectMarshaler) error {
+	return e.ArrayEncoder.AppendObject(redactObject{m, e.r})
+}
+
+func (e *redactArrayEncoder) AppendReflected(v interface{}) error {
+	redacted, keep, changed := e.r.reflected(e.key, v)
+	switch {
+	case !changed:
+		return e.ArrayEncoder.AppendReflected(v)
+	case keep:
+		return e.ArrayEncoder.AppendReflected(redacted)
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SlogHandler slog.Handler writing through the logger from the context
+// (the global one by default), so slog and the package functions
+// produce identical lines including the AddKV fields & trace_id/span_id.
+//
+//	slog.SetDefault(slog.New(logger.NewSlogHandler()))
+type SlogHandler struct {
+	// groups Opened groups, the first one is the unnamed root
+	groups []slogGroup
+}
+
+// slogGroup Fields added to a group by WithAttrs
+type slogGroup struct {
+	name   string
+	fields []zap.Field
+}
+
+var _ slog.Handler = (*SlogHandler)(nil)
+
+// NewSlogHandler create a new slog.Handler backed by the context logger
+func NewSlogHandler() *SlogHandler {
+	return &SlogHandler{
+		groups: []slogGroup{{}},
+	}
+}
+
+func (h *SlogHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	return loggerFromContext(slogContext(ctx)).Desugar().Core().Enabled(slogToZapLevel(lvl))
+}
+
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	ctx = slogContext(ctx)
+
+	l := loggerFromContext(ctx).Desugar()
+	ce := l.Check(slogToZapLevel(r.Level), r.Message)
+	if ce == nil {
+		return nil
+	}
+
+	if !r.Time.IsZero() {
+		ce.Time = r.Time
+	}
+	// report the slog caller instead of the handler
+	if ce.Caller.Defined && r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		ce.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+		ce.Caller.Function = frame.Function
+	}
+
+	groups := make([]slogGroup, len(h.groups))
+	copy(groups, h.groups)
+
+	last := &groups[len(groups)-1]
+	// cap the slices so appending never writes to the handler ones
+	last.fields = last.fields[:len(last.fields):len(last.fields)]
+	r.Attrs(func(attr slog.Attr) bool {
+		last.fields = appendSlogAttr(last.fields, attr)
+		return true
+	})
+
+	// nest the groups from the innermost one,
+	// empty groups are omitted as slog requires
+	for i := len(groups) - 1; i > 0; i-- {
+		if len(groups[i].fields) == 0 {
+			continue
+		}
+		parent := &groups[i-1]
+		parent.fields = append(parent.fields[:len(parent.fields):len(parent.fields)], zap.Object(groups[i].name, slogObject(groups[i].fields)))
+	}
+
+	kvs := make([]any, len(groups[0].fields))
+	for i := range groups[0].fields {
+		kvs[i] = groups[0].fields[i]
+	}
+
+	merged := mergeKvs(ctx, kvs...)
+	fields := make([]zap.Field, len(merged))
+	for i := range merged {
+		fields[i] = merged[i].(zap.Field)
+	}
+
+	ce.Write(fields...)
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	groups := make([]slogGroup, len(h.groups))
+	copy(groups, h.groups)
+
+	last := &groups[len(groups)-1]
+	fields := make([]zap.Field, len(last.fields), len(last.fields)+len(attrs))
+	copy(fields, last.fields)
+	for _, attr := range attrs {
+		fields = appendSlogAttr(fields, attr)
+	}
+	last.fields = fields
+
+	return &SlogHandler{groups: groups}
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	groups := make([]slogGroup, len(h.groups), len(h.groups)+1)
+	copy(groups, h.groups)
+
+	return &SlogHandler{groups: append(groups, slogGroup{name: name})}
+}
+
+// slogContext slog passes a nil context for the functions without one
+func slogContext(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
+
+func slogToZapLevel(lvl slog.Level) zapcore.Level {
+	switch {
+	case lvl < slog.LevelInfo:
+		return zapcore.DebugLevel
+	case lvl < slog.LevelWarn:
+		return zapcore.InfoLevel
+	case lvl < slog.LevelError:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
+
+// appendSlogAttr Convert the attribute to zap fields following the slog.Handler rules
+func appendSlogAttr(fields []zap.Field, attr slog.Attr) []zap.Field {
+	attr.Value = attr.Value.Resolve()
+
+	switch attr.Value.Kind() {
+	case slog.KindGroup:
+		attrs := attr.Value.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+
+		// a group without a key is inlined
+		if attr.Key == "" {
+			for _, a := range attrs {
+				fields = appendSlogAttr(fields, a)
+			}
+			return fields
+		}
+
+		group := make([]zap.Field, 0, len(attrs))
+		for _, a := range attrs {
+			group = appendSlogAttr(group, a)
+		}
+		return append(fields, zap.Object(attr.Key, slogObject(group)))
+	}
+
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		return append(fields, zap.String(attr.Key, attr.Value.String()))
+	case slog.KindInt64:
+		return append(fields, zap.Int64(attr.Key, attr.Value.Int64()))
+	case slog.KindUint64:
+		return append(fields, zap.Uint64(attr.Key, attr.Value.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, zap.Float64(attr.Key, attr.Value.Float64()))
+	case slog.KindBool:
+		return append(fields, zap.Bool(attr.Key, attr.Value.Bool()))
+	case slog.KindDuration:
+		return append(fields, zap.Duration(attr.Key, attr.Value.Duration()))
+	case slog.KindTime:
+		return append(fields, zap.Time(attr.Key, attr.Value.Time()))
+	}
+
+	if err, ok := attr.Value.Any().(error); ok {
+		return append(fields, zap.NamedError(attr.Key, err))
+	}
+	return append(fields, zap.Any(attr.Key, attr.Value.Any()))
+}
+
+// slogObject Fields of a slog group rendered as a nested object
+type slogObject []zap.Field
+
+func (o slogObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for i := range o {
+		o[i].AddTo(enc)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSlogHandler(t *testing.T) {
+	t.Parallel()
+
+	buf := bytes.Buffer{}
+	ctx := ToContext(context.Background(), loggerWithWriter(&buf))
+	ctx = AddKV(ctx, "request_id", "abc", "user", "ctx-user")
+
+	tID, err := trace.TraceIDFromHex("55e02c160e0dbd1b441bf1d5dc3ea3d5")
+	require.NoError(t, err)
+	sID, err := trace.SpanIDFromHex("a48b167265f65931")
+	require.NoError(t, err)
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: tID,
+		SpanID:  sID,
+	}))
+
+	l := slog.New(NewSlogHandler()).With("component", "db").WithGroup("query").With("table", "apples")
+	l.WarnContext(ctx, "slow query",
+		"took", time.Second,
+		"user", "caller-user",
+		slog.Group("args", "id", 5, "active", true),
+		slog.Group("empty"),
+	)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+
+	require.Equal(t, "slow query", decoded["message"])
+	require.Equal(t, "warn", decoded["level"])
+	require.Equal(t, "db", decoded["component"])
+	require.Equal(t, "abc", decoded["request_id"])
+	require.Equal(t, "ctx-user", decoded["user"])
+	require.Equal(t, "55e02c160e0dbd1b441bf1d5dc3ea3d5", decoded["trace_id"])
+	require.Equal(t, "a48b167265f65931", decoded["span_id"])
+	require.Equal(t, map[string]interface{}{
+		"table": "apples",
+		"took":  1.0,
+		"user":  "caller-user",
+		"args": map[string]interface{}{
+			"id":     5.0,
+			"active": true,
+		},
+	}, decoded["query"])
+}
+
+func TestSlogHandlerMatchesKV(t *testing.T) {
+	t.Parallel()
+
+	buf := bytes.Buffer{}
+	ctx := ToContext(context.Background(), loggerWithWriter(&buf))
+	ctx = AddKV(ctx, "request_id", "abc")
+
+	InfoKV(ctx, "created", "order_id", 5, "price", 9.99, "paid", true, "err", errors.New("boom"))
+	kvLine := bytes.Clone(buf.Bytes())
+	buf.Reset()
+
+	r := slog.NewRecord(time.Time{}, slog.LevelInfo, "created", 0)
+	r.AddAttrs(slog.Int("order_id", 5), slog.Float64("price", 9.99), slog.Bool("paid", true), slog.Any("err", errors.New("boom")))
+	require.NoError(t, NewSlogHandler().Handle(ctx, r))
+	slogLine := buf.Bytes()
+
+	var kvDecoded, slogDecoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(kvLine, &kvDecoded))
+	require.NoError(t, json.Unmarshal(slogLine, &slogDecoded))
+	delete(kvDecoded, "ts")
+	delete(slogDecoded, "ts")
+	require.Equal(t, kvDecoded, slogDecoded)
+}
+
+func TestSlogHandlerLevels(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		level slog.Level
+		want  zapcore.Level
+	}{
+		{level: slog.LevelDebug - 4, want: zapcore.DebugLevel},
+		{level: slog.LevelDebug, want: zapcore.DebugLevel},
+		{level: slog.LevelInfo, want: zapcore.InfoLevel},
+		{level: slog.LevelInfo + 2, want: zapcore.InfoLevel},
+		{level: slog.LevelWarn, want: zapcore.WarnLevel},
+		{level: slog.LevelError, want: zapcore.ErrorLevel},
+		{level: slog.LevelError + 4, want: zapcore.ErrorLevel},
+	}
+
+	for _, tc := range cases {
+		require.Equal(t, tc.want, slogToZapLevel(tc.level), tc.level.String())
+	}
+
+	core, logs := observer.New(zapcore.WarnLevel)
+	ctx := ToContext(context.Background(), zap.New(core).Sugar())
+	h := NewSlogHandler()
+
+	require.False(t, h.Enabled(ctx, slog.LevelInfo))
+	require.True(t, h.Enabled(ctx, slog.LevelWarn))
+
+	l := slog.New(h)
+	l.InfoContext(ctx, "skipped")
+	l.ErrorContext(ctx, "logged")
+
+	require.Len(t, logs.All(), 1)
+	require.Equal(t, "logged", logs.All()[0].Message)
+}
+
+func TestSlogHandlerCaller(t *testing.T) {
+	t.Parallel()
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	ctx := ToContext(context.Background(), zap.New(core, zap.AddCaller()).Sugar())
+
+	slog.New(NewSlogHandler()).InfoContext(ctx, "hello world")
+
+	require.Len(t, logs.All(), 1)
+	require.True(t, logs.All()[0].Caller.Defined)
+	require.Contains(t, logs.All()[0].Caller.File, "slog_test.go")
+}
@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// WithSpanEvents Mirror the entries at or above the level logged through a context
+// with a recording span onto the span:
+//
+//	logger.New(nil, logger.WithSpanEvents(zapcore.WarnLevel))
+//
+// Every entry is added as a span event named after the message with the fields
+// (including AddKV) as attributes, the error fields are recorded with RecordError
+// and the entries at or above Error set the span status to Error.
+func WithSpanEvents(level zapcore.Level) zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &spanEventCore{Core: core, level: level}
+	})
+}
+
+// spanEventCore Core mirroring the entries written by the wrapped one onto the context span
+type spanEventCore struct {
+	zapcore.Core
+	level  zapcore.Level
+	fields []zapcore.Field
+	span   trace.Span
+}
+
+func (c *spanEventCore) Level() zapcore.Level {
+	return zapcore.LevelOf(c.Core)
+}
+
+func (c *spanEventCore) levelFor(name string) zapcore.Level {
+	return effectiveLevel(c.Core, name)
+}
+
+func (c *spanEventCore) usesSpan() bool {
+	return true
+}
+
+func (c *spanEventCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.Core = c.Core.With(withoutSpan(c.Core, fields))
+	clone.fields = append(c.fields[:len(c.fields):len(c.fields)], fields...)
+	if span, ok := spanOf(fields); ok {
+		clone.span = span
+	}
+	return &clone
+}
+
+func (c *spanEventCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.levelFor(ent.LoggerName).Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *spanEventCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	err := c.Core.Write(ent, fields)
+	if ent.Level < c.level {
+		return err
+	}
+
+	span := c.span
+	if fieldSpan, ok := spanOf(fields); ok {
+		span = fieldSpan
+	}
+	if span == nil || !span.IsRecording() {
+		return err
+	}
+
+	attrs := append(entryAttributes(ent, c.fields, fields), attribute.String("level", ent.Level.String()))
+	attrs = withoutTraceFields(attrs, span.SpanContext())
+	span.AddEvent(ent.Message, trace.WithTimestamp(ent.Time), trace.WithAttributes(attrs...))
+
+	for _, f := range [][]zapcore.Field{c.fields, fields} {
+		for i := range f {
+			if fieldErr, ok := f[i].Interface.(error); ok && f[i].Type == zapcore.ErrorType {
+				span.RecordError(fieldErr, trace.WithTimestamp(ent.Time))
+			}
+		}
+	}
+
+	if ent.Level >= zapcore.ErrorLevel {
+		span.SetStatus(codes.Error, ent.Message)
+	}
+	return err
+}
+
+// withoutTraceFields Drop the trace fields from the attributes, redundant on the span
+func withoutTraceFields(attrs []attribute.KeyValue, spanCtx trace.SpanContext) []attribute.KeyValue {
+	traceFields := CurrentTraceFormatter().Fields(spanCtx)
+	kept := attrs[:0]
+	for _, attr := range attrs {
+		if !hasKey(traceFields, string(attr.Key)) {
+			kept = append(kept, attr)
+		}
+	}
+	return kept
+}
+
+func hasKey(fields []zapcore.Field, key string) bool {
+	for i := range fields {
+		if fields[i].Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWithSpanEvents(t *testing.T) {
+	t.Parallel()
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	buf := bytes.Buffer{}
+	l := NewWithSink(zapcore.DebugLevel, &buf, WithSpanEvents(zapcore.WarnLevel))
+	ctx, span := tracer.Start(ToContext(context.Background(), l), "GetApples")
+	ctx = AddKV(ctx, "request_id", "42")
+
+	InfoKV(ctx, "fetching apples", "count", 5)
+	WarnKV(ctx, "slow query", "duration_ms", 1200)
+	ErrorKV(ctx, "fetch failed", "error", errors.New("connection reset"), "attempt", 3)
+	span.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, codes.Error, spans[0].Status().Code)
+	require.Equal(t, "fetch failed", spans[0].Status().Description)
+
+	events := spans[0].Events()
+	require.Len(t, events, 3)
+
+	require.Equal(t, "slow query", events[0].Name)
+	require.Equal(t, []attribute.KeyValue{
+		attribute.Int64("duration_ms", 1200),
+		attribute.String("request_id", "42"),
+		attribute.String("level", "warn"),
+	}, events[0].Attributes)
+
+	require.Equal(t, "fetch failed", events[1].Name)
+	require.Equal(t, []attribute.KeyValue{
+		attribute.Int64("attempt", 3),
+		attribute.String("error", "connection reset"),
+		attribute.String("request_id", "42"),
+		attribute.String("level", "error"),
+	}, events[1].Attributes)
+
+	require.Equal(t, "exception", events[2].Name)
+	require.Contains(t, events[2].Attributes, attribute.String("exception.message", "connection reset"))
+
+	// the logger output is unchanged
+	require.Equal(t, 3, bytes.Count(buf.Bytes(), []byte("\n")))
+}
+
+func TestWithSpanEventsWithoutRecordingSpan(t *testing.T) {
+	t.Parallel()
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	buf := bytes.Buffer{}
+	l := NewWithSink(zapcore.InfoLevel, &buf, WithSpanEvents(zapcore.WarnLevel))
+	ctx := ToContext(context.Background(), l)
+
+	// no span
+	Error(ctx, "no span")
+
+	// a span context without a span
+	Error(trace.ContextWithSpanContext(ctx, testSpanContext(t, "a48b167265f65931")), "remote span")
+
+	// an ended span
+	spanCtx, span := tracer.Start(ctx, "ended")
+	span.End()
+	Error(spanCtx, "ended span")
+
+	require.Len(t, recorder.Ended(), 1)
+	require.Empty(t, recorder.Ended()[0].Events())
+	require.Equal(t, codes.Unset, recorder.Ended()[0].Status().Code)
+	require.Equal(t, 3, bytes.Count(buf.Bytes(), []byte("\n")))
+}
@@ -0,0 +1,166 @@
+package logger
+
+import (
+	"io"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// TeeOutput One of the destinations of a logger created by NewTee
+type TeeOutput struct {
+	// Sink writer the entries are written to
+	Sink io.Writer
+	// Level entries below it are not written to this output,
+	// nil writes every entry logged by the logger
+	Level zapcore.LevelEnabler
+	// Encoder encoder of the output, nil uses the default JSON one
+	Encoder zapcore.Encoder
+	// Filter fields written to this output, nil keeps all of them (see ExcludeFields)
+	Filter func(zapcore.Field) bool
+}
+
+// NewTee create a new logger writing to several outputs.
+//
+// The level decides which entries are logged as for NewWithSink (a nil level follows
+// the global log_level & the per logger name overrides apply), then every output
+// writes the ones at or above its own level:
+//
+//	logger.NewTee(zapcore.DebugLevel, []logger.TeeOutput{
+//		{Sink: os.Stdout, Level: zapcore.InfoLevel},
+//		{Sink: file, Encoder: logger.NewConsoleEncoder(cfg, false)},
+//		{Sink: os.Stderr, Level: zapcore.ErrorLevel, Filter: logger.ExcludeFields("request")},
+//	})
+func NewTee(level zapcore.LevelEnabler, outputs []TeeOutput, options ...zap.Option) *zap.SugaredLogger {
+	if level == nil {
+		level = defaultLevel
+	}
+
+	tee := &teeCore{outputs: make([]teeOutput, 0, len(outputs))}
+	for _, o := range outputs {
+		enc := o.Encoder
+		if enc == nil {
+			enc = zapcore.NewJSONEncoder(defaultEncoderConfig())
+		}
+
+		tee.outputs = append(tee.outputs, teeOutput{
+			core:   zapcore.NewCore(enc, zapcore.AddSync(o.Sink), zapcore.DebugLevel),
+			level:  o.Level,
+			filter: o.Filter,
+		})
+	}
+
+	return zap.New(newLoggerCore(tee, level), options...).Sugar()
+}
+
+// ExcludeFields Output filter dropping the fields with one of the keys
+func ExcludeFields(keys ...string) func(zapcore.Field) bool {
+	excluded := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		excluded[k] = struct{}{}
+	}
+
+	return func(f zapcore.Field) bool {
+		_, ok := excluded[f.Key]
+		return !ok
+	}
+}
+
+// teeCore Core writing the entries to the outputs whose level enables them,
+// the logger level is decided by the cores wrapping it
+type teeCore struct {
+	outputs []teeOutput
+}
+
+type teeOutput struct {
+	core   zapcore.Core
+	level  zapcore.LevelEnabler
+	filter func(zapcore.Field) bool
+}
+
+func (o *teeOutput) enabled(l zapcore.Level) bool {
+	return o.level == nil || o.level.Enabled(l)
+}
+
+// fields Get the fields kept by the filter, the slice is copied only if some are dropped
+func (o *teeOutput) fields(fields []zapcore.Field) []zapcore.Field {
+	if o.filter == nil {
+		return fields
+	}
+
+	for i := range fields {
+		if o.filter(fields[i]) {
+			continue
+		}
+
+		kept := make([]zapcore.Field, i, len(fields)-1)
+		copy(kept, fields[:i])
+		for _, f := range fields[i+1:] {
+			if o.filter(f) {
+				kept = append(kept, f)
+			}
+		}
+		return kept
+	}
+	return fields
+}
+
+// Level Get the lowest level written by one of the outputs
+func (c *teeCore) Level() zapcore.Level {
+	lvl := zapcore.InvalidLevel
+	for i := range c.outputs {
+		o := &c.outputs[i]
+		if o.level == nil {
+			return zapcore.DebugLevel
+		}
+		if outputLvl := zapcore.LevelOf(o.level); lvl == zapcore.InvalidLevel || outputLvl < lvl {
+			lvl = outputLvl
+		}
+	}
+	return lvl
+}
+
+func (c *teeCore) Enabled(l zapcore.Level) bool {
+	for i := range c.outputs {
+		if c.outputs[i].enabled(l) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *teeCore) With(fields []zapcore.Field) zapcore.Core {
+	outputs := make([]teeOutput, len(c.outputs))
+	for i, o := range c.outputs {
+		o.core = o.core.With(o.fields(fields))
+		outputs[i] = o
+	}
+	return &teeCore{outputs: outputs}
+}
+
+func (c *teeCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *teeCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	var err error
+	for i := range c.outputs {
+		o := &c.outputs[i]
+		if o.enabled(ent.Level) {
+			err = multierr.Append(err, o.core.Write(ent, o.fields(fields)))
+		}
+	}
+	return err
+}
+
+func (c *teeCore) Sync() error {
+	var err error
+	for i := range c.outputs {
+		err = multierr.Append(err, c.outputs[i].core.Sync())
+	}
+	return err
+}
@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewTee(t *testing.T) {
+	var stdout, file, errs bytes.Buffer
+	l := NewTee(zapcore.DebugLevel, []TeeOutput{
+		{Sink: &stdout, Level: zapcore.InfoLevel},
+		{Sink: &file, Encoder: NewConsoleEncoder(defaultEncoderConfig(), false)},
+		{Sink: &errs, Level: zapcore.ErrorLevel, Filter: ExcludeFields("user_id")},
+	})
+
+	ctx := ToContext(context.Background(), l)
+	ctx = trace.ContextWithSpanContext(ctx, testSpanContext(t, "a48b167265f65931"))
+	ctx = AddKV(ctx, "request_id", "42", "user_id", 7)
+	ctx = WithName(ctx, "orders")
+
+	Debug(ctx, "debug message")
+	InfoKV(ctx, "info message", "user_id", 8)
+	FromContext(ctx).Errorw("error message", "attempt", 3)
+
+	stdoutLines := decodeLines(t, &stdout)
+	require.Len(t, stdoutLines, 2)
+	require.Equal(t, "info message", stdoutLines[0]["message"])
+	require.Equal(t, "error message", stdoutLines[1]["message"])
+	for _, line := range stdoutLines {
+		require.Equal(t, "orders", line["logger"])
+		require.Equal(t, "42", line["request_id"])
+		require.Equal(t, "55e02c160e0dbd1b441bf1d5dc3ea3d5", line["trace_id"])
+		require.Equal(t, "a48b167265f65931", line["span_id"])
+	}
+	require.EqualValues(t, 8, stdoutLines[0]["user_id"])
+	require.EqualValues(t, 7, stdoutLines[1]["user_id"])
+
+	fileLines := strings.Split(strings.TrimSpace(file.String()), "\n")
+	require.Len(t, fileLines, 3)
+	require.Contains(t, fileLines[0], "[DBG]")
+	require.Contains(t, fileLines[0], "debug message")
+	require.Contains(t, fileLines[0], "trace_id=55e02c160e0dbd1b441bf1d5dc3ea3d5")
+	require.Contains(t, fileLines[1], "user_id=8")
+
+	errLines := decodeLines(t, &errs)
+	require.Len(t, errLines, 1)
+	require.Equal(t, "error message", errLines[0]["message"])
+	require.EqualValues(t, 3, errLines[0]["attempt"])
+	require.Equal(t, "42", errLines[0]["request_id"])
+	require.NotContains(t, errLines[0], "user_id")
+
+	require.NoError(t, l.Sync())
+}
+
+func TestNewTeeLevels(t *testing.T) {
+	t.Cleanup(func() { nameLevels.set(nil) })
+
+	var info, debug bytes.Buffer
+	level := SharedLevel("TestNewTeeLevels")
+	level.SetLevel(zapcore.WarnLevel)
+
+	l := NewTee(level, []TeeOutput{
+		{Sink: &info, Level: zapcore.InfoLevel},
+		{Sink: &debug, Level: zapcore.DebugLevel},
+	})
+	ctx := ToContext(context.Background(), l)
+	dbCtx := WithName(ctx, "db")
+
+	// the logger level applies to all the outputs
+	Info(ctx, "hidden")
+	require.Zero(t, info.Len())
+	require.Zero(t, debug.Len())
+	require.Equal(t, zapcore.WarnLevel, LevelFromContext(ctx))
+
+	// the overrides lower it, the output levels still apply
+	require.NoError(t, SetNameLevel("db", zapcore.DebugLevel))
+	Debug(dbCtx, "db query")
+	require.Zero(t, info.Len())
+	require.Contains(t, debug.String(), "db query")
+	require.Equal(t, zapcore.DebugLevel, LevelFromContext(dbCtx))
+
+	// the logger can't log below its lowest output
+	onlyInfo := ToContext(context.Background(), NewTee(zapcore.DebugLevel, []TeeOutput{{Sink: &info, Level: zapcore.InfoLevel}}))
+	require.Equal(t, zapcore.InfoLevel, LevelFromContext(onlyInfo))
+}
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+
+	var lines []map[string]interface{}
+	for _, raw := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if raw == "" {
+			continue
+		}
+
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(raw), &line))
+		lines = append(lines, line)
+	}
+	return lines
+}
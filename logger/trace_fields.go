@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// traceFormatterConfig Current trace formatter, the generation changes with it
+// so the loggers enriched by the previous one aren't reused
+type traceFormatterConfig struct {
+	formatter  TraceFormatter
+	generation uint64
+}
+
+// currentTraceFormatter is set before any init function runs, so the one of
+// LOG_TRACE_FORMAT stored by the init of env.go isn't overwritten
+var (
+	traceFormatterGeneration atomic.Uint64
+	currentTraceFormatter    = func() *atomic.Pointer[traceFormatterConfig] {
+		var p atomic.Pointer[traceFormatterConfig]
+		p.Store(&traceFormatterConfig{formatter: DefaultTraceFields()})
+		return &p
+	}()
+)
+
+// TraceFormatter Formats the fields injected from the context span by FromContext
+// & the package functions (see TraceFields, DatadogTraceFormatter, GCPTraceFormatter,
+// XRayTraceFormatter & ECSTraceFormatter)
+type TraceFormatter interface {
+	Fields(spanCtx trace.SpanContext) []zap.Field
+}
+
+// SetTraceFormatter Set the formatter of the fields injected from the context span
+// by all the loggers, safe to call while logging concurrently
+func SetTraceFormatter(f TraceFormatter) {
+	currentTraceFormatter.Store(&traceFormatterConfig{
+		formatter:  f,
+		generation: traceFormatterGeneration.Add(1),
+	})
+}
+
+// CurrentTraceFormatter Get the formatter of the fields injected from the context span
+func CurrentTraceFormatter() TraceFormatter {
+	return currentTraceFormatter.Load().formatter
+}
+
+// TraceFields Formatter injecting the span context with the OpenTelemetry encoding
+// under the keys, an empty key omits the field
+type TraceFields struct {
+	TraceID string
+	SpanID  string
+	// TraceFlags the W3C trace flags as hex, e.g. "01"
+	TraceFlags string
+	// Sampled whether the trace is sampled
+	Sampled string
+	// TraceState the W3C trace state, omitted when empty
+	TraceState string
+	// Remote whether the span context was propagated from a remote parent
+	Remote string
+}
+
+// DefaultTraceFields Get the trace fields injected by default, trace_id & span_id
+func DefaultTraceFields() TraceFields {
+	return TraceFields{
+		TraceID: "trace_id",
+		SpanID:  "span_id",
+	}
+}
+
+// AllTraceFields Get all the trace fields with their default keys
+func AllTraceFields() TraceFields {
+	return TraceFields{
+		TraceID:    "trace_id",
+		SpanID:     "span_id",
+		TraceFlags: "trace_flags",
+		Sampled:    "sampled",
+		TraceState: "trace_state",
+		Remote:     "remote",
+	}
+}
+
+// SetTraceFields Set the fields injected from the context span by all the loggers,
+// a shorthand for SetTraceFormatter(fields)
+func SetTraceFields(fields TraceFields) {
+	SetTraceFormatter(fields)
+}
+
+// Fields Get the fields of the span context
+func (f TraceFields) Fields(spanCtx trace.SpanContext) []zap.Field {
+	fields := make([]zap.Field, 0, 6)
+	if f.TraceID != "" {
+		fields = append(fields, zap.Stringer(f.TraceID, spanCtx.TraceID()))
+	}
+	if f.SpanID != "" {
+		fields = append(fields, zap.Stringer(f.SpanID, spanCtx.SpanID()))
+	}
+	if f.TraceFlags != "" {
+		fields = append(fields, zap.Stringer(f.TraceFlags, spanCtx.TraceFlags()))
+	}
+	if f.Sampled != "" {
+		fields = append(fields, zap.Bool(f.Sampled, spanCtx.IsSampled()))
+	}
+	if f.TraceState != "" && spanCtx.TraceState().Len() > 0 {
+		fields = append(fields, zap.Stringer(f.TraceState, spanCtx.TraceState()))
+	}
+	if f.Remote != "" {
+		fields = append(fields, zap.Bool(f.Remote, spanCtx.IsRemote()))
+	}
+	return fields
+}
@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSetTraceFields(t *testing.T) {
+	t.Cleanup(func() { SetTraceFields(DefaultTraceFields()) })
+
+	state, err := trace.ParseTraceState("vendor=abc")
+	require.NoError(t, err)
+	spanCtx := testSpanContext(t, "a48b167265f65931").
+		WithTraceFlags(trace.FlagsSampled).
+		WithTraceState(state).
+		WithRemote(true)
+
+	buf := bytes.Buffer{}
+	ctx := ToContext(context.Background(), NewWithSink(zapcore.DebugLevel, &buf))
+	ctx = trace.ContextWithSpanContext(ctx, spanCtx)
+
+	testCases := []struct {
+		name     string
+		fields   TraceFields
+		expected map[string]interface{}
+	}{
+		{
+			name:   "default",
+			fields: DefaultTraceFields(),
+			expected: map[string]interface{}{
+				"trace_id": "55e02c160e0dbd1b441bf1d5dc3ea3d5",
+				"span_id":  "a48b167265f65931",
+			},
+		},
+		{
+			name:   "all",
+			fields: AllTraceFields(),
+			expected: map[string]interface{}{
+				"trace_id":    "55e02c160e0dbd1b441bf1d5dc3ea3d5",
+				"span_id":     "a48b167265f65931",
+				"trace_flags": "01",
+				"sampled":     true,
+				"trace_state": "vendor=abc",
+				"remote":      true,
+			},
+		},
+		{
+			name:   "renamed",
+			fields: TraceFields{TraceID: "traceId", Sampled: "traceSampled"},
+			expected: map[string]interface{}{
+				"traceId":      "55e02c160e0dbd1b441bf1d5dc3ea3d5",
+				"traceSampled": true,
+			},
+		},
+		{
+			name:     "none",
+			fields:   TraceFields{},
+			expected: map[string]interface{}{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf.Reset()
+			SetTraceFields(tc.fields)
+			require.Equal(t, tc.fields, CurrentTraceFormatter())
+
+			// the logger enriched with the previous fields isn't reused
+			Info(ctx, "message")
+
+			lines := decodeLines(t, &buf)
+			require.Len(t, lines, 1)
+			for _, key := range []string{"ts", "level", "message"} {
+				delete(lines[0], key)
+			}
+			require.Equal(t, tc.expected, lines[0])
+		})
+	}
+}
+
+func TestTraceFieldsWithoutTraceState(t *testing.T) {
+	t.Parallel()
+
+	fields := AllTraceFields().Fields(testSpanContext(t, "a48b167265f65931"))
+
+	keys := make([]string, 0, len(fields))
+	for _, f := range fields {
+		keys = append(keys, f.Key)
+	}
+	require.Equal(t, []string{"trace_id", "span_id", "trace_flags", "sampled", "remote"}, keys)
+}
+
+func TestTraceFieldsNotReusedAcrossSpanContexts(t *testing.T) {
+	SetTraceFields(AllTraceFields())
+	t.Cleanup(func() { SetTraceFields(DefaultTraceFields()) })
+
+	state, err := trace.ParseTraceState("vendor=abc")
+	require.NoError(t, err)
+	remote := testSpanContext(t, "a48b167265f65931").WithRemote(true)
+
+	buf := bytes.Buffer{}
+	ctx := ToContext(context.Background(), NewWithSink(zapcore.DebugLevel, &buf))
+
+	Info(trace.ContextWithSpanContext(ctx, remote), "remote")
+	Info(trace.ContextWithSpanContext(ctx, remote.WithRemote(false)), "local")
+	Info(trace.ContextWithSpanContext(ctx, remote.WithTraceState(state)), "state")
+
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 3)
+	require.Equal(t, true, lines[0]["remote"])
+	require.Equal(t, false, lines[1]["remote"])
+	require.NotContains(t, lines[1], "trace_state")
+	require.Equal(t, "vendor=abc", lines[2]["trace_state"])
+}
@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Trace formats accepted by ParseTraceFormat & LOG_TRACE_FORMAT
+const (
+	TraceFormatDefault = "default"
+	TraceFormatAll     = "all"
+	TraceFormatDatadog = "datadog"
+	TraceFormatGCP     = "gcp"
+	TraceFormatXRay    = "xray"
+	TraceFormatECS     = "ecs"
+)
+
+// DatadogTraceFormatter Injects dd.trace_id & dd.span_id as the decimal lower 64 bits
+// of the ids, the service, env & version are added when set
+type DatadogTraceFormatter struct {
+	Service string
+	Env     string
+	Version string
+}
+
+// Fields Get the fields of the span context
+func (f DatadogTraceFormatter) Fields(spanCtx trace.SpanContext) []zap.Field {
+	traceID, spanID := spanCtx.TraceID(), spanCtx.SpanID()
+	fields := []zap.Field{
+		zap.String("dd.trace_id", strconv.FormatUint(binary.BigEndian.Uint64(traceID[8:]), 10)),
+		zap.String("dd.span_id", strconv.FormatUint(binary.BigEndian.Uint64(spanID[:]), 10)),
+	}
+	if f.Service != "" {
+		fields = append(fields, zap.String("dd.service", f.Service))
+	}
+	if f.Env != "" {
+		fields = append(fields, zap.String("dd.env", f.Env))
+	}
+	if f.Version != "" {
+		fields = append(fields, zap.String("dd.version", f.Version))
+	}
+	return fields
+}
+
+// GCPTraceFormatter Injects the Cloud Logging trace, span & sampled fields,
+// the trace being projects/<ProjectID>/traces/<trace id>
+type GCPTraceFormatter struct {
+	ProjectID string
+}
+
+// Fields Get the fields of the span context
+func (f GCPTraceFormatter) Fields(spanCtx trace.SpanContext) []zap.Field {
+	return []zap.Field{
+		zap.String("logging.googleapis.com/trace", "projects/"+f.ProjectID+"/traces/"+spanCtx.TraceID().String()),
+		zap.Stringer("logging.googleapis.com/spanId", spanCtx.SpanID()),
+		zap.Bool("logging.googleapis.com/trace_sampled", spanCtx.IsSampled()),
+	}
+}
+
+// XRayTraceFormatter Injects xray_trace_id in the AWS X-Ray format, 1-<8 hex digits>-<24 hex digits>.
+// The trace ids must be generated by the X-Ray id generator for the first part to be the trace start time.
+type XRayTraceFormatter struct{}
+
+// Fields Get the fields of the span context
+func (XRayTraceFormatter) Fields(spanCtx trace.SpanContext) []zap.Field {
+	traceID := spanCtx.TraceID()
+	return []zap.Field{
+		zap.String("xray_trace_id", "1-"+hex.EncodeToString(traceID[:4])+"-"+hex.EncodeToString(traceID[4:])),
+	}
+}
+
+// ECSTraceFormatter Injects the Elastic Common Schema trace.id & span.id
+type ECSTraceFormatter struct{}
+
+// Fields Get the fields of the span context
+func (ECSTraceFormatter) Fields(spanCtx trace.SpanContext) []zap.Field {
+	return []zap.Field{
+		zap.Stringer("trace.id", spanCtx.TraceID()),
+		zap.Stringer("span.id", spanCtx.SpanID()),
+	}
+}
+
+// TraceFormatters Formatter injecting the fields of all the formatters
+type TraceFormatters []TraceFormatter
+
+// Fields Get the fields of the span context
+func (fs TraceFormatters) Fields(spanCtx trace.SpanContext) []zap.Field {
+	var fields []zap.Field
+	for _, f := range fs {
+		fields = append(fields, f.Fields(spanCtx)...)
+	}
+	return fields
+}
+
+// ParseTraceFormat Get the formatter of comma separated formats: default, all, datadog, gcp, xray & ecs.
+// The project of gcp is set as gcp:<project id> or read from GOOGLE_CLOUD_PROJECT,
+// the service, env & version of datadog are read from DD_SERVICE, DD_ENV & DD_VERSION.
+func ParseTraceFormat(s string) (TraceFormatter, error) {
+	var formatters TraceFormatters
+	for _, format := range splitList(s) {
+		name, arg, _ := strings.Cut(format, ":")
+		switch strings.ToLower(name) {
+		case TraceFormatDefault:
+			formatters = append(formatters, DefaultTraceFields())
+		case TraceFormatAll:
+			formatters = append(formatters, AllTraceFields())
+		case TraceFormatDatadog:
+			formatters = append(formatters, DatadogTraceFormatter{
+				Service: os.Getenv("DD_SERVICE"),
+				Env:     os.Getenv("DD_ENV"),
+				Version: os.Getenv("DD_VERSION"),
+			})
+		case TraceFormatGCP:
+			if arg == "" {
+				arg = os.Getenv("GOOGLE_CLOUD_PROJECT")
+			}
+			if arg == "" {
+				return nil, fmt.Errorf("trace format %q: missing the project id, expected gcp:<project id> or GOOGLE_CLOUD_PROJECT", format)
+			}
+			formatters = append(formatters, GCPTraceFormatter{ProjectID: arg})
+		case TraceFormatXRay:
+			formatters = append(formatters, XRayTraceFormatter{})
+		case TraceFormatECS:
+			formatters = append(formatters, ECSTraceFormatter{})
+		default:
+			return nil, fmt.Errorf("unknown trace format %q", format)
+		}
+	}
+
+	switch len(formatters) {
+	case 0:
+		return DefaultTraceFields(), nil
+	case 1:
+		return formatters[0], nil
+	}
+	return formatters, nil
+}
@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestTraceFormatters(t *testing.T) {
+	t.Parallel()
+
+	spanCtx := testSpanContext(t, "a48b167265f65931").WithTraceFlags(trace.FlagsSampled)
+
+	testCases := []struct {
+		name      string
+		formatter TraceFormatter
+		expected  map[string]interface{}
+	}{
+		{
+			name:      "datadog",
+			formatter: DatadogTraceFormatter{Service: "orders", Env: "prod"},
+			expected: map[string]interface{}{
+				"dd.trace_id": "4907782119775708117",
+				"dd.span_id":  "11856595124575689009",
+				"dd.service":  "orders",
+				"dd.env":      "prod",
+			},
+		},
+		{
+			name:      "gcp",
+			formatter: GCPTraceFormatter{ProjectID: "my-project"},
+			expected: map[string]interface{}{
+				"logging.googleapis.com/trace":         "projects/my-project/traces/55e02c160e0dbd1b441bf1d5dc3ea3d5",
+				"logging.googleapis.com/spanId":        "a48b167265f65931",
+				"logging.googleapis.com/trace_sampled": true,
+			},
+		},
+		{
+			name:      "xray",
+			formatter: XRayTraceFormatter{},
+			expected: map[string]interface{}{
+				"xray_trace_id": "1-55e02c16-0e0dbd1b441bf1d5dc3ea3d5",
+			},
+		},
+		{
+			name:      "ecs",
+			formatter: ECSTraceFormatter{},
+			expected: map[string]interface{}{
+				"trace.id": "55e02c160e0dbd1b441bf1d5dc3ea3d5",
+				"span.id":  "a48b167265f65931",
+			},
+		},
+		{
+			name:      "combined",
+			formatter: TraceFormatters{DefaultTraceFields(), ECSTraceFormatter{}},
+			expected: map[string]interface{}{
+				"trace_id": "55e02c160e0dbd1b441bf1d5dc3ea3d5",
+				"span_id":  "a48b167265f65931",
+				"trace.id": "55e02c160e0dbd1b441bf1d5dc3ea3d5",
+				"span.id":  "a48b167265f65931",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := bytes.Buffer{}
+			l := loggerWithSpan(NewWithSink(zapcore.DebugLevel, &buf), trace.SpanFromContext(trace.ContextWithSpanContext(context.Background(), spanCtx)), tc.formatter)
+			l.Info("message")
+
+			lines := decodeLines(t, &buf)
+			require.Len(t, lines, 1)
+			for _, key := range []string{"ts", "level", "message"} {
+				delete(lines[0], key)
+			}
+			require.Equal(t, tc.expected, lines[0])
+		})
+	}
+}
+
+func TestSetTraceFormatter(t *testing.T) {
+	t.Cleanup(func() { SetTraceFormatter(DefaultTraceFields()) })
+
+	buf := bytes.Buffer{}
+	ctx := ToContext(context.Background(), NewWithSink(zapcore.DebugLevel, &buf))
+	ctx = trace.ContextWithSpanContext(ctx, testSpanContext(t, "a48b167265f65931"))
+
+	Info(ctx, "default")
+	SetTraceFormatter(ECSTraceFormatter{})
+	Info(ctx, "ecs")
+
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 2)
+	require.Equal(t, "a48b167265f65931", lines[0]["span_id"])
+	require.NotContains(t, lines[1], "span_id")
+	require.Equal(t, "a48b167265f65931", lines[1]["span.id"])
+}
+
+func TestParseTraceFormat(t *testing.T) {
+	t.Setenv("DD_SERVICE", "orders")
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+
+	testCases := []struct {
+		format   string
+		expected TraceFormatter
+		err      string
+	}{
+		{format: "", expected: DefaultTraceFields()},
+		{format: "all", expected: AllTraceFields()},
+		{format: "Datadog", expected: DatadogTraceFormatter{Service: "orders"}},
+		{format: "gcp:my-project", expected: GCPTraceFormatter{ProjectID: "my-project"}},
+		{format: "default,ecs", expected: TraceFormatters{DefaultTraceFields(), ECSTraceFormatter{}}},
+		{format: "gcp", err: `trace format "gcp": missing the project id, expected gcp:<project id> or GOOGLE_CLOUD_PROJECT`},
+		{format: "xray,zipkin", err: `unknown trace format "zipkin"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			formatter, err := ParseTraceFormat(tc.format)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, formatter)
+		})
+	}
+}